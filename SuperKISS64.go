@@ -31,7 +31,8 @@ get the result I do. It should take around 20 seconds.
 // $Id: SuperKISS64.go,v 2.17 2024-07-08 06:36:37-04 ron Exp $
 
 // To run the GM test suggested above, type "go test" in this file's
-// directory.  SuperKISS64_test.go and cryptosource.go must also be present.
+// directory.  SuperKISS64_test.go, cryptosource.go and stats.go must also
+// be present.
 
 // To find 10^397524 on Windows (with unxutils' GNU awk, bc, tr & wc):
 // awk "BEGIN{printf(\"5*2^^1320480*(2^^64-1)\n\")}" | bc -q | tr -cd "0-9" | wc -c
@@ -58,15 +59,31 @@ get the result I do. It should take around 20 seconds.
 package SuperKISS64
 
 import (
+	"bufio"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/binary"
+	"encoding/json"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"hash"
+	"hash/fnv"
 	"io"
+	"iter"
 	"math"
+	"math/big"
+	"math/bits"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 // Ported by RC from GM C code:
@@ -74,15 +91,43 @@ import (
 // QSIZE64 specifies len(SK64.Q).
 const QSIZE64 = 20632
 
+// Period returns SuperKISS64's period, 5*2^1320480*(2^64-1), which has
+// 397525 decimal digits.  Each call returns a new big.Int.
+func Period() *big.Int {
+	p := new(big.Int).Lsh(big.NewInt(1), 64)
+	p.Sub(p, big.NewInt(1))
+	p.Mul(p, big.NewInt(5))
+	return p.Lsh(p, 1320480)
+}
+
+// StateCount returns the number of distinct starting states, and thus of
+// distinct sequences, that SuperKISS64 offers: one per position in its
+// single cycle, so more than 10^397524.  It equals Period.  Each call
+// returns a new big.Int.
+func StateCount() *big.Int {
+	return Period()
+}
+
 // SK64 is the state for SuperKISS64 methods.  SuperKISS64's period is
 // more than 10^397524.
 type SK64 struct {
-	Carry  uint64   `xml:"Carry"`
-	Xcng   uint64   `xml:"Xcng"`
-	Xs     uint64   `xml:"Xs"`
-	Index  uint64   `xml:"Index"`
-	Q      []uint64 `xml:"Q"`
-	Seeded bool     `xml:"Seeded"`
+	Carry  uint64
+	Xcng   uint64
+	Xs     uint64
+	Index  uint64
+	Q      []uint64
+	Seeded bool
+	// Bits holds NBits buffered pseudorandom bits not yet used by Bool,
+	// Uint32, Int31 or ReadByte.
+	Bits  uint64
+	NBits uint64
+	// Drawn counts the Uint64 values generated since r was last seeded or
+	// ResetCount was called; see Count.  It is a diagnostic only and is
+	// ignored by Equal and Fingerprint.
+	Drawn uint64
+	// seed is a copy of the slice given to SeedFromSlice, for SeedSlice.
+	// It is not part of the saved state.
+	seed []uint64
 }
 
 // cng is a congruential pseudorandom number generator (PRNG) for internal
@@ -100,9 +145,16 @@ func xs(x uint64) uint64 {
 	return x
 }
 
-// RC code:
+// XorShift returns the next state after x of the 64-bit Xor-Shift PRNG that
+// SuperKISS64 uses internally, with George Marsaglia's shift triple
+// (13, 17, 43).  That triple gives XorShift the full period: starting from
+// any nonzero x, repeated calls visit all 2^64-1 nonzero values before
+// returning to x.  Zero maps to zero, and no nonzero value maps to zero.
+func XorShift(x uint64) uint64 {
+	return xs(x)
+}
 
-var vvv uint64
+// RC code:
 
 // New allocates a SuperKISS64 PRNG and initializes it with a "random" seed.
 // It is useful when repeating a sequence is not required.
@@ -113,6 +165,20 @@ func New() *SK64 {
 	return NewSuperKISS64(n)
 }
 
+// parallelCount makes each NewParallel seed distinct.
+var parallelCount atomic.Uint64
+
+// NewParallel allocates a SuperKISS64 PRNG and initializes it with a
+// "random" seed, like New, but mixes the nanosecond clock with an
+// atomically incremented package-level counter so that calls made at the
+// same instant, such as from many goroutines, get distinct seeds.  It is
+// safe for concurrent use.
+func NewParallel() *SK64 {
+	c := parallelCount.Add(1)
+	x := uint64(time.Now().UnixNano()) ^ splitmix64(&c)
+	return NewSuperKISS64(int64(splitmix64(&x)))
+}
+
 // NewSuperKISS64 allocates a new SuperKISS64 PRNG.  Parameter seed determines
 // whether or not to initialize for testing.
 // Seed with 0 for George Marsaglia's test; otherwise use any int64 seed.
@@ -128,6 +194,12 @@ func New() *SK64 {
 // and r.Shuffle().
 // See function TestSK64SaveLoadWrapped in SuperKISS64_test.go for an
 // example of how to save and load the state of a wrapped generator.
+//
+// SK64 also implements the math/rand/v2.Source interface, so it can be
+// wrapped with math/rand/v2.New without an adapter:
+//
+//	import randv2 "math/rand/v2"
+//	r := randv2.New(NewSuperKISS64(seed))
 func NewSuperKISS64(seed int64) *SK64 {
 	r := &SK64{
 		Q: make([]uint64, QSIZE64),
@@ -185,16 +257,355 @@ func NewSuperKISS64FromSlice(s []uint64) *SK64 {
 	return r
 }
 
+// NewSuperKISS64FromSliceCopy is like NewSuperKISS64FromSlice, but seeds
+// from a private copy of s, so another goroutine changing s during the
+// call cannot affect seeding.  The private copy is zeroed before
+// returning, and the generator keeps no copy for SeedSlice, so the caller
+// may then reuse or wipe s without affecting the generator.
+func NewSuperKISS64FromSliceCopy(s []uint64) *SK64 {
+	c := slices.Clone(s)
+	r := &SK64{
+		Q: make([]uint64, QSIZE64),
+	}
+	r.seedFromSlice(c)
+	clear(c)
+	return r
+}
+
 // NewSuperKISS64Array is provided for compatibility with older versions.
 // It is deprecated.  Use NewSuperKISS64FromSlice in new code.
 func NewSuperKISS64Array(q []uint64) *SK64 {
 	return NewSuperKISS64FromSlice(q)
 }
 
+// NewSuperKISS64FromString allocates a new SuperKISS64 PRNG and
+// initializes it from string s as SeedFromString does.  The same string
+// always yields the same sequence.
+func NewSuperKISS64FromString(s string) *SK64 {
+	r := &SK64{
+		Q: make([]uint64, QSIZE64),
+	}
+	r.SeedFromString(s)
+	return r
+}
+
+// NewSuperKISS64FromHash allocates a new SuperKISS64 PRNG and initializes
+// it from the digest of h as SeedFromHash does.  Hashes with the same
+// digest always yield the same sequence.
+func NewSuperKISS64FromHash(h hash.Hash64) *SK64 {
+	r := &SK64{
+		Q: make([]uint64, QSIZE64),
+	}
+	r.SeedFromHash(h)
+	return r
+}
+
+// NewKeyedStream allocates a new SuperKISS64 PRNG seeded from key as
+// SeedFromBytes does, without recording key for SeedSlice, for use as a
+// keyed byte stream through Read.  XORing data with the stream masks it,
+// and XORing again with a fresh stream from the same key unmasks it.  This
+// is reproducible obfuscation of non-sensitive data, NOT encryption:
+// SuperKISS64 is not cryptographically secure and its state can be
+// recovered from its output.
+func NewKeyedStream(key []byte) *SK64 {
+	r := &SK64{
+		Q: make([]uint64, QSIZE64),
+	}
+	r.seedFromSlice(bytesToSeed(key))
+	return r
+}
+
+// NewForTask allocates a new SuperKISS64 PRNG for task taskIndex of the
+// parallel job identified by runID, for example a cluster array job.  The
+// same (runID, taskIndex) pair always yields the same sequence.  The seed
+// slice passed to SeedFromSlice holds runID and taskIndex unchanged,
+// followed by SplitMix64 output from a mix of both, so distinct pairs
+// always give distinct seed slices and, in practice, distinct sequences.
+func NewForTask(runID int64, taskIndex int) *SK64 {
+	r := &SK64{
+		Q: make([]uint64, QSIZE64),
+	}
+	s := make([]uint64, QSIZE64)
+	s[0], s[1] = uint64(runID), uint64(taskIndex)
+	x := s[0]
+	x = splitmix64(&x) ^ s[1]
+	for i := 2; i < len(s); i++ {
+		s[i] = splitmix64(&x)
+	}
+	r.seedFromSlice(s)
+	return r
+}
+
+// splitmix64 advances state *x and returns the next value of Sebastiano
+// Vigna's SplitMix64 generator.  It is used to expand small seeds into
+// well-mixed seed material.
+func splitmix64(x *uint64) uint64 {
+	*x += 0x9e3779b97f4a7c15
+	z := *x
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// NewSubstreams allocates count SuperKISS64 PRNGs derived deterministically
+// from masterSeed.  The master seed is expanded by SplitMix64 into a
+// distinct QSIZE64-element seed slice for each generator, which is passed
+// to SeedFromSlice.  Given SuperKISS64's enormous period the substreams do
+// not overlap in practice, and the same masterSeed and count always yield
+// the same substreams.  NewSubstreams panics if count < 0.
+func NewSubstreams(masterSeed int64, count int) []*SK64 {
+	if count < 0 {
+		panic("SuperKISS64:NewSubstreams called with count < 0")
+	}
+	x := uint64(masterSeed)
+	s := make([]uint64, QSIZE64)
+	streams := make([]*SK64, count)
+	for k := range streams {
+		for i := range s {
+			s[i] = splitmix64(&x)
+		}
+		streams[k] = &SK64{
+			Q: make([]uint64, QSIZE64),
+		}
+		streams[k].seedFromSlice(s)
+	}
+	return streams
+}
+
+// Clone returns a deep copy of SuperKISS64 generator r.  The clone has its
+// own Q slice, so using either generator never affects the other.  Both
+// generators produce identical sequences until one of them is reseeded.
+// Clone returns nil if r is nil.
+func (r *SK64) Clone() *SK64 {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	c.Q = append([]uint64(nil), r.Q...)
+	if r.seed != nil {
+		c.seed = append([]uint64{}, r.seed...)
+	}
+	return &c
+}
+
+// Equal reports whether generators r and other have identical states,
+// including every element of Q, so they will generate the same sequence.
+// The Drawn diagnostics counter is not compared.  Two nil generators are
+// equal; a nil and a non-nil generator are not.
+func (r *SK64) Equal(other *SK64) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if r.Carry != other.Carry || r.Xcng != other.Xcng || r.Xs != other.Xs ||
+		r.Index != other.Index || r.Seeded != other.Seeded ||
+		r.Bits != other.Bits || r.NBits != other.NBits ||
+		len(r.Q) != len(other.Q) {
+		return false
+	}
+	for i, q := range r.Q {
+		if q != other.Q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a concise summary of the state of r, without the
+// elements of Q, for logging and debugging.  For example:
+//
+//	SK64{Seeded:true Index:42 Xcng:0x... Xs:0x... Carry:0x... Q[len=20632]}
+//
+// String returns "SK64(nil)" if r is nil.  This method implements the
+// fmt.Stringer interface.
+func (r *SK64) String() string {
+	if r == nil {
+		return "SK64(nil)"
+	}
+	return fmt.Sprintf("SK64{Seeded:%v Index:%d Xcng:%#x Xs:%#x Carry:%#x "+
+		"Q[len=%d]}", r.Seeded, r.Index, r.Xcng, r.Xs, r.Carry, len(r.Q))
+}
+
+// Fingerprint returns a 64-bit FNV-1a hash of the complete state of r,
+// including every element of Q but not the Drawn diagnostics counter.
+// Generators with equal states have equal fingerprints on every run and
+// architecture, which makes Fingerprint handy for asserting in tests that
+// a loaded generator has an expected state.  Fingerprint returns 0 if r is nil.
+func (r *SK64) Fingerprint() uint64 {
+	if r == nil {
+		return 0
+	}
+	h := fnv.New64a()
+	var seeded byte
+	if r.Seeded {
+		seeded = 1
+	}
+	b := make([]byte, 0, 4096)
+	for _, v := range []uint64{
+		r.Carry, r.Xcng, r.Xs, r.Index, r.Bits, r.NBits,
+	} {
+		b = binary.LittleEndian.AppendUint64(b, v)
+	}
+	b = append(b, seeded)
+	for _, q := range r.Q {
+		if len(b)+8 > cap(b) {
+			h.Write(b)
+			b = b[:0]
+		}
+		b = binary.LittleEndian.AppendUint64(b, q)
+	}
+	h.Write(b)
+	return h.Sum64()
+}
+
+// sk64XML is the XML form of SK64 used by MarshalXML and UnmarshalXML.
+// MarshalXML writes all of Q as one base64 Q element.  Older versions
+// wrote Q as space-separated decimal values, in one Q element or one per
+// value, which UnmarshalXML also reads.
+type sk64XML struct {
+	Carry  uint64
+	Xcng   uint64
+	Xs     uint64
+	Index  uint64
+	Q      []qXML
+	Seeded bool
+	Bits   uint64
+	NBits  uint64
+	Drawn  uint64
+}
+
+// qXML is a Q element of sk64XML.  Encoding is "base64" for the
+// little-endian bytes of Q in standard base64, or empty for decimal.
+type qXML struct {
+	Encoding string `xml:"encoding,attr,omitempty"`
+	Text     string `xml:",chardata"`
+}
+
+// MarshalXML writes the state of SuperKISS64 PRNG r as an XML element with
+// one child element per field, except that all of Q is written as a single
+// Q element holding the standard base64 encoding of its little-endian
+// bytes.  That is about half the size of decimal Q values.  This method
+// implements the encoding/xml.Marshaler interface.
+func (r *SK64) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	q := make([]byte, 0, 8*len(r.Q))
+	for _, v := range r.Q {
+		q = binary.LittleEndian.AppendUint64(q, v)
+	}
+	return e.EncodeElement(sk64XML{
+		Carry: r.Carry,
+		Xcng:  r.Xcng,
+		Xs:    r.Xs,
+		Index: r.Index,
+		Q: []qXML{{
+			Encoding: "base64",
+			Text:     base64.StdEncoding.EncodeToString(q),
+		}},
+		Seeded: r.Seeded,
+		Bits:   r.Bits,
+		NBits:  r.NBits,
+		Drawn:  r.Drawn,
+	}, start)
+}
+
+// UnmarshalXML sets the state of r from an XML element written by
+// MarshalXML, or by older versions that wrote Q as decimal values.  An
+// error is returned if Q is not valid base64 or decimal or the state does
+// not have exactly QSIZE64 Q values.  If an error occurs r is left
+// unchanged.  This method implements the encoding/xml.Unmarshaler
+// interface.
+func (r *SK64) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var x sk64XML
+	if err := d.DecodeElement(&x, &start); err != nil {
+		return err
+	}
+	q := make([]uint64, 0, QSIZE64)
+	for _, elem := range x.Q {
+		switch elem.Encoding {
+		case "base64":
+			b, err := base64.StdEncoding.DecodeString(
+				strings.TrimSpace(elem.Text))
+			if err != nil {
+				return fmt.Errorf("SuperKISS64:UnmarshalXML found bad Q: %w",
+					err)
+			}
+			if len(b)%8 != 0 {
+				return fmt.Errorf("SuperKISS64:UnmarshalXML found %d Q bytes; "+
+					"want a multiple of 8", len(b))
+			}
+			for ; len(b) > 0; b = b[8:] {
+				q = append(q, binary.LittleEndian.Uint64(b))
+			}
+		case "":
+			for _, f := range strings.Fields(elem.Text) {
+				v, err := strconv.ParseUint(f, 10, 64)
+				if err != nil {
+					return fmt.Errorf("SuperKISS64:UnmarshalXML found bad Q "+
+						"value: %w", err)
+				}
+				q = append(q, v)
+			}
+		default:
+			return fmt.Errorf("SuperKISS64:UnmarshalXML found unknown Q "+
+				"encoding %q", elem.Encoding)
+		}
+	}
+	if err := checkQ("UnmarshalXML", q); err != nil {
+		return err
+	}
+	*r = SK64{
+		Carry:  x.Carry,
+		Xcng:   x.Xcng,
+		Xs:     x.Xs,
+		Index:  x.Index,
+		Q:      q,
+		Seeded: x.Seeded,
+		Bits:   x.Bits,
+		NBits:  x.NBits,
+		Drawn:  x.Drawn,
+	}
+	return nil
+}
+
+// WriteState writes the state of SuperKISS64 PRNG r as XML to w, in the
+// form MarshalXML gives.  The written state is about 220 KB.  See also
+// WriteStateGzip.  The state can be read back by calling ReadState.
+func (r *SK64) WriteState(w io.Writer) (err error) {
+	if r == nil {
+		return errors.New("SuperKISS64:WriteState called with nil r")
+	}
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return
+	}
+	e := xml.NewEncoder(w)
+	defer func() {
+		err = errors.Join(err, e.Close())
+	}()
+	err = e.Encode(r)
+	return
+}
+
+// WriteStateGzip writes the state of SuperKISS64 PRNG r as gzip'ped XML to
+// w.  The typical written state is about 166 KB.  The state can be read
+// back by calling ReadStateGzip.
+func (r *SK64) WriteStateGzip(w io.Writer) (err error) {
+	var gw *gzip.Writer
+
+	if r == nil {
+		return errors.New("SuperKISS64:WriteStateGzip called with nil r")
+	}
+	if gw, err = gzip.NewWriterLevel(w, gzip.BestCompression); err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, gw.Close())
+	}()
+	err = r.WriteState(gw)
+	return
+}
+
 // SaveState saves the state of SuperKISS64 PRNG r as XML to a file named
-// by outfile.  The saved file size is about 524 KB.
+// by outfile.  The saved file size is about 220 KB.
 // If outfile ends with ".gz" a gzip'ped XML file is saved, and
-// the typical saved file size is about 212 KB.  Either type of saved state
+// the typical saved file size is about 166 KB.  Either type of saved state
 // file can be loaded by calling either SK64LoadState or LoadState with the
 // same file name used to save the file.
 //
@@ -207,30 +618,53 @@ func NewSuperKISS64Array(q []uint64) *SK64 {
 //	$ gzip -cd myFile.xml.gz | xmllint --format - | less
 func (r *SK64) SaveState(outfile string) (err error) {
 	var out *os.File
-	var gw *gzip.Writer
 
 	if r == nil {
 		return errors.New("SuperKISS64:SaveState called with nil r")
 	}
+	defer fileError(&err, "SaveState", "save state to", outfile)
 	if out, err = os.Create(outfile); err != nil {
 		return
 	}
 	defer func() {
 		err = errors.Join(err, out.Close())
 	}()
-	w := io.Writer(out)
 	if strings.HasSuffix(outfile, ".gz") {
-		best := gzip.BestCompression
-		if gw, err = gzip.NewWriterLevel(out, best); err != nil {
-			return
-		}
-		defer func() {
-			err = errors.Join(err, gw.Close())
-		}()
-		w = gw
+		err = r.WriteStateGzip(out)
+	} else {
+		err = r.WriteState(out)
+	}
+	return
+}
+
+// SaveStateCompact saves the state of SuperKISS64 PRNG r to a file named by
+// outfile as the most compact XML this package writes: the MarshalXML
+// form without an XML declaration, always gzip'ped at the best compression
+// level whatever outfile is called.  The typical file is about 166 KB.
+// A smaller file, such as under 100 KB, is not possible: Q alone holds
+// QSIZE64 pseudorandom 64-bit values, 165 KB that no lossless encoding can
+// compress.  Load the state with LoadStateCompact or LoadState.
+func (r *SK64) SaveStateCompact(outfile string) (err error) {
+	var out *os.File
+	var gw *gzip.Writer
+
+	if r == nil {
+		return errors.New("SuperKISS64:SaveStateCompact called with nil r")
+	}
+	defer fileError(&err, "SaveStateCompact", "save state to", outfile)
+	if out, err = os.Create(outfile); err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+	if gw, err = gzip.NewWriterLevel(out, gzip.BestCompression); err != nil {
+		return
 	}
-	io.WriteString(w, xml.Header)
-	e := xml.NewEncoder(w)
+	defer func() {
+		err = errors.Join(err, gw.Close())
+	}()
+	e := xml.NewEncoder(gw)
 	defer func() {
 		err = errors.Join(err, e.Close())
 	}()
@@ -238,10 +672,30 @@ func (r *SK64) SaveState(outfile string) (err error) {
 	return
 }
 
+// LoadStateCompact loads SuperKISS64 state r from a file saved earlier
+// with SaveStateCompact.  It is equivalent to LoadState, which also reads
+// such files.  If an error occurs r is left unchanged.
+func (r *SK64) LoadStateCompact(infile string) error {
+	if r == nil {
+		return errors.New("SuperKISS64:LoadStateCompact called with nil r")
+	}
+	return r.LoadState(infile)
+}
+
+// fileError wraps a non-nil *err from caller with a message naming the
+// file that caller failed to load or save.  The wrapped error can still be
+// examined with errors.Is and errors.As.
+func fileError(err *error, caller, action, name string) {
+	if *err != nil {
+		*err = fmt.Errorf("SuperKISS64:%s failed to %s %q: %w", caller, action,
+			name, *err)
+	}
+}
+
 // SK64SaveState saves a SuperKISS64 state r to an XML file named by outfile.
-// The file size is about 524 KB.
+// The file size is about 220 KB.
 // If outfile ends with ".gz" SK64SaveState saves a gzip'ped XML file;
-// then the typical saved file size is about 212 KB.  Either type of saved
+// then the typical saved file size is about 166 KB.  Either type of saved
 // state file can be loaded by calling either SK64LoadState or LoadState
 // with the same file name used to save the file.
 func SK64SaveState(r *SK64, outfile string) (err error) {
@@ -251,46 +705,77 @@ func SK64SaveState(r *SK64, outfile string) (err error) {
 	return r.SaveState(outfile)
 }
 
+// ReadState reads SuperKISS64 state r as XML from rd, as written earlier
+// by WriteState, including by older versions that wrote one Q element per
+// value.  An error is returned if the state does not have exactly QSIZE64
+// Q values.  If an error occurs r is left unchanged.
+func (r *SK64) ReadState(rd io.Reader) (err error) {
+	if r == nil {
+		return errors.New("SuperKISS64:ReadState called with nil r")
+	}
+	q := &SK64{}
+	decoder := xml.NewDecoder(rd)
+	if err = decoder.Decode(q); err != nil {
+		return
+	}
+	*r = *q
+	return
+}
+
+// ReadStateGzip reads SuperKISS64 state r as gzip'ped XML from rd, as
+// written earlier by WriteStateGzip.  If an error occurs r is left
+// unchanged.
+func (r *SK64) ReadStateGzip(rd io.Reader) (err error) {
+	var gr *gzip.Reader
+
+	if r == nil {
+		return errors.New("SuperKISS64:ReadStateGzip called with nil r")
+	}
+	if gr, err = gzip.NewReader(rd); err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, gr.Close())
+	}()
+	err = r.ReadState(gr)
+	return
+}
+
 // LoadState loads SuperKISS64 state r from an XML state file saved earlier
 // with SaveState or SK64SaveState.
-// Infile should match the file name used to save the state.
-// If infile ends with ".gz" then LoadState expects a gzip'ped XML file.
+// LoadState detects a gzip'ped XML file by its leading magic bytes
+// (0x1f 0x8b) rather than its name, so a gzip'ped state loads whatever
+// infile is called.
+// An error is returned if the state does not have exactly QSIZE64 Q
+// values, as in a truncated or hand-edited file.
 // If an error occurs r is left unchanged.
 func (r *SK64) LoadState(infile string) (err error) {
 	var in *os.File
-	var gr *gzip.Reader
 
 	if r == nil {
 		return errors.New("SuperKISS64:LoadState called with nil r")
 	}
+	defer fileError(&err, "LoadState", "load state from", infile)
 	if in, err = os.Open(infile); err != nil {
 		return
 	}
 	defer func() {
 		err = errors.Join(err, in.Close())
 	}()
-	rdr := io.Reader(in)
-	if strings.HasSuffix(infile, ".gz") {
-		if gr, err = gzip.NewReader(in); err != nil {
-			return
-		}
-		defer func() {
-			err = errors.Join(err, gr.Close())
-		}()
-		rdr = gr
-	}
-	q := &SK64{}
-	decoder := xml.NewDecoder(rdr)
-	if err = decoder.Decode(q); err == nil {
-		*r = *q
+	br := bufio.NewReader(in)
+	if magic, _ := br.Peek(2); string(magic) == "\x1f\x8b" {
+		err = r.ReadStateGzip(br)
+	} else {
+		err = r.ReadState(br)
 	}
 	return
 }
 
 // SK64LoadState returns a SuperKISS64 generator r loaded from an
-// XML state file saved earlier with SaveState or SK64SaveState.  Infile should
-// match the file name used to save the state.  If infile ends with ".gz"
-// then SK64LoadState expects a gzip'ped XML file.
+// XML state file saved earlier with SaveState or SK64SaveState.  Like
+// LoadState, it detects a gzip'ped XML file by its content rather than its
+// name.  An error is returned if the state does not have exactly QSIZE64 Q
+// values.
 // (nil, err) is returned if an error occurs.
 func SK64LoadState(infile string) (r *SK64, err error) {
 	r = &SK64{}
@@ -300,77 +785,525 @@ func SK64LoadState(infile string) (r *SK64, err error) {
 	return
 }
 
-// Seed added to C code by Ron Charlton in 2017.
-
-// Seed initializes a SuperKISS64 instance r with seed.
-// Call with seed == 0 for SuperKISS64_test.go:TestSuperKISS64.  Or call with
-// a seed of any int64 value.  For a "random" seed,
-// call Seed with argument time.Now().UnixNano(), as New does.
-func (r *SK64) Seed(seed int64) {
-	r.Seeded = true
+// SaveStateBinary saves the state of SuperKISS64 PRNG r to a file named by
+// outfile in the binary layout of MarshalBinary.  The saved file size is
+// about 165 KB, much smaller and faster to load than the XML written by
+// SaveState.  If outfile ends with ".gz" a gzip'ped file is saved.  The
+// saved state can be loaded by calling LoadStateBinary with the same file
+// name used to save the file.
+func (r *SK64) SaveStateBinary(outfile string) (err error) {
+	var out *os.File
+	var gw *gzip.Writer
+	var b []byte
 
-	if seed == 0 {
-		r.Xcng = 12367890123456
-	} else {
-		r.Xcng = uint64(seed)
+	if r == nil {
+		return errors.New("SuperKISS64:SaveStateBinary called with nil r")
 	}
-	r.Xs = 521288629546311
-	r.Carry = 36243678541
-	r.Index = QSIZE64
-	for i := 0; i < QSIZE64; i++ {
-		r.Xs = xs(r.Xs)
-		r.Q[i] = r.cng() + r.Xs
+	defer fileError(&err, "SaveStateBinary", "save state to", outfile)
+	if b, err = r.MarshalBinary(); err != nil {
+		return
 	}
-
-	// warm up the generator
-	if seed != 0 {
-		for i := 0; i < (QSIZE64 * 4); i++ {
-			vvv = r.Uint64()
+	if out, err = os.Create(outfile); err != nil {
+		return
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+	}()
+	w := io.Writer(out)
+	if strings.HasSuffix(outfile, ".gz") {
+		gw, err = gzip.NewWriterLevel(out, gzip.BestCompression)
+		if err != nil {
+			return
 		}
+		defer func() {
+			err = errors.Join(err, gw.Close())
+		}()
+		w = gw
 	}
+	_, err = w.Write(b)
+	return
 }
 
-// SeedArray added to C code by Ron Charlton on 2020-09-05.
-
-// SeedFromSlice provides a full range of repeatable initializations (Seed has
-// only 2^63-1 starting points with math/rand). Call SeedFromSlice with a
-// slice of one or more random uint64 numbers.
-// It is best to call SeedFromSlice with QSIZE64 random numbers, although
-// any number of values is acceptable. If len(s) > QSIZE64, only the first
-// QSIZE64 elements in s are used.
-func (r *SK64) SeedFromSlice(s []uint64) {
-	var i, j, n uint64
-	count := uint64(len(s))
-	r.Seeded = true
-
-	r.Xcng = 12367890123456
-	r.Xs = 521288629546311
-	r.Carry = 36243678541
-	r.Index = QSIZE64
+// LoadStateBinary loads SuperKISS64 state r from a binary state file saved
+// earlier with SaveStateBinary.  Infile should match the file name used to
+// save the state.  If infile ends with ".gz" then LoadStateBinary expects
+// a gzip'ped file.  If an error occurs r is left unchanged.
+func (r *SK64) LoadStateBinary(infile string) (err error) {
+	var in *os.File
+	var gr *gzip.Reader
+	var b []byte
 
-	if count > 0 {
-		r.Xs = xs(r.Xs)
-		r.Q[0] = s[j] + r.cng() + r.Xs
-		j++
-		for i = 1; i < QSIZE64; i++ {
-			n = r.Q[i-1]
-			j %= count // in this location in case count is 1
-			r.Q[i] = s[j] + r.cng() + xs(n) + i
-			j++
-		}
-		r.Q[0] = s[j%count] + r.cng() + xs(r.Q[i-1]) + i
-	} else {
-		for i = 0; i < QSIZE64; i++ {
-			r.Xs = xs(r.Xs)
-			r.Q[i] = r.cng() + r.Xs
-		}
+	if r == nil {
+		return errors.New("SuperKISS64:LoadStateBinary called with nil r")
 	}
-
-	// warm up the generator
-	for i = 0; i < (QSIZE64 * 4); i++ {
-		vvv = r.Uint64()
+	defer fileError(&err, "LoadStateBinary", "load state from", infile)
+	if in, err = os.Open(infile); err != nil {
+		return
 	}
-}
+	defer func() {
+		err = errors.Join(err, in.Close())
+	}()
+	rdr := io.Reader(in)
+	if strings.HasSuffix(infile, ".gz") {
+		if gr, err = gzip.NewReader(in); err != nil {
+			return
+		}
+		defer func() {
+			err = errors.Join(err, gr.Close())
+		}()
+		rdr = gr
+	}
+	if b, err = io.ReadAll(rdr); err != nil {
+		return
+	}
+	err = r.UnmarshalBinary(b)
+	return
+}
+
+// Validate checks the invariants of the state of SuperKISS64 PRNG r, such
+// as one just loaded from an external source, and returns an error naming
+// the first one violated, or nil if the state is consistent.  It checks
+// that Seeded is true, Q has QSIZE64 elements, Index is in [0,QSIZE64],
+// Xs is nonzero (a zero Xor-Shift state stays zero forever), and NBits is
+// at most 64.
+func (r *SK64) Validate() error {
+	switch {
+	case r == nil:
+		return errors.New("SuperKISS64:Validate called with nil r")
+	case !r.Seeded:
+		return errors.New("SuperKISS64:Validate found Seeded false")
+	case len(r.Q) != QSIZE64:
+		return checkQ("Validate", r.Q)
+	case r.Index > QSIZE64:
+		return fmt.Errorf("SuperKISS64:Validate found Index %d; "+
+			"want at most %d", r.Index, QSIZE64)
+	case r.Xs == 0:
+		return errors.New("SuperKISS64:Validate found Xs 0, a degenerate state")
+	case r.NBits > 64:
+		return fmt.Errorf("SuperKISS64:Validate found NBits %d; "+
+			"want at most 64", r.NBits)
+	}
+	return nil
+}
+
+// selfTestDraws is how many values SelfTest examines.
+const selfTestDraws = 64
+
+// SelfTest is a fast liveness probe for a generator in production.  It
+// checks r with Validate and returns an error if more than a few of 64
+// Q values spread across Q repeat one another, as when Q has been
+// overwritten with one value.  It then examines the next 64 values r
+// would generate and returns an error if they are all identical or more
+// than a few are zero.  SelfTest does not change the state of r: it
+// draws from a shallow copy, cloning Q only if a refill falls within the
+// 64 values.
+func (r *SK64) SelfTest() error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	var sample [selfTestDraws]uint64
+	for i := range sample {
+		sample[i] = r.Q[i*(QSIZE64/selfTestDraws)]
+	}
+	slices.Sort(sample[:])
+	repeats := 0
+	for i := 1; i < len(sample); i++ {
+		if sample[i] == sample[i-1] {
+			repeats++
+		}
+	}
+	if repeats > 2 { // about 64*64/2*2^-64 repeats are expected
+		return fmt.Errorf("SuperKISS64:SelfTest found %d repeats in "+
+			"%d Q values", repeats, selfTestDraws)
+	}
+	c := *r // shares Q, which draws modify only when refilling
+	if c.Index+selfTestDraws > QSIZE64 {
+		c.Q = slices.Clone(r.Q)
+	}
+	first := c.Uint64()
+	same, zeros := 1, 0
+	if first == 0 {
+		zeros++
+	}
+	for i := 1; i < selfTestDraws; i++ {
+		v := c.Uint64()
+		if v == first {
+			same++
+		}
+		if v == 0 {
+			zeros++
+		}
+	}
+	switch {
+	case same == selfTestDraws:
+		return fmt.Errorf("SuperKISS64:SelfTest found %d identical values %#x",
+			selfTestDraws, first)
+	case zeros > 2: // about 64*2^-64 zeros are expected
+		return fmt.Errorf("SuperKISS64:SelfTest found %d zeros in %d values",
+			zeros, selfTestDraws)
+	}
+	return nil
+}
+
+// checkQ returns a descriptive error if q is not a valid SK64.Q for a
+// state being loaded by caller.
+func checkQ(caller string, q []uint64) error {
+	if len(q) != QSIZE64 {
+		return fmt.Errorf("SuperKISS64:%s found %d Q values; want %d",
+			caller, len(q), QSIZE64)
+	}
+	return nil
+}
+
+// sk64JSON has the fields of SK64 without its methods, so encoding/json
+// can encode and decode it without recursing into MarshalJSON or
+// UnmarshalJSON.
+type sk64JSON SK64
+
+// MarshalJSON returns the state of SuperKISS64 PRNG r as a JSON object
+// with Q as an array of numbers.  This method implements the
+// encoding/json.Marshaler interface.
+func (r *SK64) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, errors.New("SuperKISS64:MarshalJSON called with nil r")
+	}
+	return json.Marshal((*sk64JSON)(r))
+}
+
+// UnmarshalJSON sets the state of r from JSON produced by MarshalJSON.
+// An error is returned if Q does not have exactly QSIZE64 elements.
+// If an error occurs r is left unchanged.  This method implements the
+// encoding/json.Unmarshaler interface.
+func (r *SK64) UnmarshalJSON(data []byte) error {
+	if r == nil {
+		return errors.New("SuperKISS64:UnmarshalJSON called with nil r")
+	}
+	var q sk64JSON
+	if err := json.Unmarshal(data, &q); err != nil {
+		return err
+	}
+	if err := checkQ("UnmarshalJSON", q.Q); err != nil {
+		return err
+	}
+	*r = SK64(q)
+	return nil
+}
+
+// Binary state layout written by MarshalBinary: the 4-byte magic
+// "SK64", a version byte, Carry, Xcng, Xs and Index as little-endian
+// uint64 values, a Seeded byte, Bits and NBits as little-endian uint64
+// values (version 2 and later), then the QSIZE64 elements of Q as
+// little-endian uint64 values.
+const (
+	binaryMagic   = "SK64"
+	binaryVersion = 3
+)
+
+// binaryBytes returns the size of a binary state of version.
+func binaryBytes(version byte) int {
+	n := len(binaryMagic) + 1 + 4*8 + 1 + QSIZE64*8
+	if version >= 2 {
+		n += 2 * 8
+	}
+	if version >= 3 {
+		n += 8
+	}
+	return n
+}
+
+// MarshalBinary returns the state of SuperKISS64 PRNG r in a deterministic
+// binary layout of about 165 KB: a small magic and version header, Carry,
+// Xcng, Xs and Index as little-endian uint64 values, a Seeded byte, Bits,
+// NBits and Drawn as little-endian uint64 values, then the QSIZE64
+// elements of Q as little-endian uint64 values.  This method implements the
+// encoding.BinaryMarshaler interface.
+func (r *SK64) MarshalBinary() ([]byte, error) {
+	return r.AppendBinary(make([]byte, 0, binaryBytes(binaryVersion)))
+}
+
+// AppendBinary appends the state of SuperKISS64 PRNG r to b in the layout
+// MarshalBinary uses and returns the extended slice, so several states can
+// be packed into one reused buffer.  This method implements the
+// encoding.BinaryAppender interface.
+func (r *SK64) AppendBinary(b []byte) ([]byte, error) {
+	if r == nil {
+		return b, errors.New("SuperKISS64:AppendBinary called with nil r")
+	}
+	if err := checkQ("AppendBinary", r.Q); err != nil {
+		return b, err
+	}
+	b = slices.Grow(b, binaryBytes(binaryVersion))
+	b = append(b, binaryMagic...)
+	b = append(b, binaryVersion)
+	b = binary.LittleEndian.AppendUint64(b, r.Carry)
+	b = binary.LittleEndian.AppendUint64(b, r.Xcng)
+	b = binary.LittleEndian.AppendUint64(b, r.Xs)
+	b = binary.LittleEndian.AppendUint64(b, r.Index)
+	var seeded byte
+	if r.Seeded {
+		seeded = 1
+	}
+	b = append(b, seeded)
+	b = binary.LittleEndian.AppendUint64(b, r.Bits)
+	b = binary.LittleEndian.AppendUint64(b, r.NBits)
+	b = binary.LittleEndian.AppendUint64(b, r.Drawn)
+	for _, q := range r.Q {
+		b = binary.LittleEndian.AppendUint64(b, q)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary sets the state of r from data produced by MarshalBinary.
+// Data written by older versions of MarshalBinary is also accepted.
+// An error is returned if data is truncated, has the wrong magic or
+// version, or does not hold exactly QSIZE64 Q values.  If an error occurs
+// r is left unchanged.  This method implements the
+// encoding.BinaryUnmarshaler interface.
+func (r *SK64) UnmarshalBinary(data []byte) error {
+	if r == nil {
+		return errors.New("SuperKISS64:UnmarshalBinary called with nil r")
+	}
+	if !strings.HasPrefix(string(data), binaryMagic) {
+		return errors.New("SuperKISS64:UnmarshalBinary found bad magic")
+	}
+	data = data[len(binaryMagic):]
+	if len(data) < 1 || data[0] < 1 || data[0] > binaryVersion {
+		return errors.New("SuperKISS64:UnmarshalBinary found unknown version")
+	}
+	version := data[0]
+	data = data[1:]
+	if n := len(binaryMagic) + 1 + len(data); n != binaryBytes(version) {
+		return fmt.Errorf("SuperKISS64:UnmarshalBinary found %d bytes; want %d",
+			n, binaryBytes(version))
+	}
+	q := SK64{
+		Carry:  binary.LittleEndian.Uint64(data[0:]),
+		Xcng:   binary.LittleEndian.Uint64(data[8:]),
+		Xs:     binary.LittleEndian.Uint64(data[16:]),
+		Index:  binary.LittleEndian.Uint64(data[24:]),
+		Seeded: data[32] != 0,
+		Q:      make([]uint64, QSIZE64),
+	}
+	data = data[33:]
+	if version >= 2 {
+		q.Bits = binary.LittleEndian.Uint64(data[0:])
+		q.NBits = binary.LittleEndian.Uint64(data[8:])
+		data = data[16:]
+	}
+	if version >= 3 {
+		q.Drawn = binary.LittleEndian.Uint64(data[0:])
+		data = data[8:]
+	}
+	for i := range q.Q {
+		q.Q[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	*r = q
+	return nil
+}
+
+// GobEncode returns the state of SuperKISS64 PRNG r in the compact binary
+// layout of MarshalBinary.  This method implements the
+// encoding/gob.GobEncoder interface.
+func (r *SK64) GobEncode() ([]byte, error) {
+	return r.MarshalBinary()
+}
+
+// GobDecode sets the state of r from a blob produced by GobEncode.  An
+// error is returned if the blob does not hold exactly QSIZE64 Q values.
+// If an error occurs r is left unchanged.  This method implements the
+// encoding/gob.GobDecoder interface.
+func (r *SK64) GobDecode(data []byte) error {
+	return r.UnmarshalBinary(data)
+}
+
+// MarshalText returns the state of SuperKISS64 PRNG r as a single line of
+// URL-safe, unpadded base64 encoding the MarshalBinary layout, about
+// 220 KB, for embedding in YAML, TOML or environment variables.  This
+// method implements the encoding.TextMarshaler interface.
+func (r *SK64) MarshalText() ([]byte, error) {
+	b, err := r.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	text := make([]byte, base64.RawURLEncoding.EncodedLen(len(b)))
+	base64.RawURLEncoding.Encode(text, b)
+	return text, nil
+}
+
+// UnmarshalText sets the state of r from text produced by MarshalText.
+// An error is returned if text is not valid base64 or does not decode to
+// a valid MarshalBinary layout.  If an error occurs r is left unchanged.
+// This method implements the encoding.TextUnmarshaler interface.
+func (r *SK64) UnmarshalText(text []byte) error {
+	b := make([]byte, base64.RawURLEncoding.DecodedLen(len(text)))
+	n, err := base64.RawURLEncoding.Decode(b, text)
+	if err != nil {
+		return fmt.Errorf("SuperKISS64:UnmarshalText: %w", err)
+	}
+	return r.UnmarshalBinary(b[:n])
+}
+
+// Seed added to C code by Ron Charlton in 2017.
+
+// Seed initializes a SuperKISS64 instance r with seed.
+// Call with seed == 0 for SuperKISS64_test.go:TestSuperKISS64.  Or call with
+// a seed of any int64 value.  For a "random" seed,
+// call Seed with argument time.Now().UnixNano(), as New does.
+func (r *SK64) Seed(seed int64) {
+	r.seedState(seed)
+	if seed != 0 {
+		r.warmUp()
+	}
+}
+
+// warmUp runs r through the QSIZE64*4 Uint64 calls that end seeding.
+func (r *SK64) warmUp() {
+	for i := 0; i < (QSIZE64 * 4); i++ {
+		r.Uint64() // result discarded; only the state change matters
+	}
+	r.Drawn = 0 // the warm-up does not count
+}
+
+// SeedFast initializes SuperKISS64 instance r with seed exactly as Seed
+// does, but skips Seed's warm-up of QSIZE64*4 Uint64 calls, which makes it
+// far faster for tests that reseed very often.  The statistical quality of
+// the first values generated after SeedFast may be slightly lower than
+// after Seed, and the two produce different sequences for nonzero seeds.
+func (r *SK64) SeedFast(seed int64) {
+	r.seedState(seed)
+}
+
+// seedState sets the state of r from seed, without the warm-up.
+func (r *SK64) seedState(seed int64) {
+	xcng := uint64(seed)
+	if seed == 0 {
+		xcng = 12367890123456
+	}
+	r.seedXcngXs(xcng, 521288629546311)
+}
+
+// seedXcngXs sets the state of r from starting values of Xcng and Xs,
+// without the warm-up.
+func (r *SK64) seedXcngXs(xcng, x uint64) {
+	r.Seeded = true
+	r.seed = nil
+	r.Bits, r.NBits, r.Drawn = 0, 0, 0
+
+	r.Xcng = xcng
+	r.Xs = x
+	r.nonzeroXs()
+	r.Carry = 36243678541
+	r.Index = QSIZE64
+	for i := 0; i < QSIZE64; i++ {
+		r.Xs = xs(r.Xs)
+		r.Q[i] = r.cng() + r.Xs
+	}
+}
+
+// Seed128 initializes SuperKISS64 instance r from two int64 values, for
+// 2^127 repeatable starting points compared with Seed's 2^63-1, without
+// the cost of building a seed slice for SeedFromSlice.  lo sets the
+// congruential component and hi perturbs the Xor-Shift component; Q is
+// then filled and warmed up as Seed does.  The Xor-Shift component must
+// not be zero, so hi == 521288629546311 keeps its default value and
+// changes the initial carry instead; distinct (hi, lo) pairs always give
+// distinct initial states.
+func (r *SK64) Seed128(hi, lo int64) {
+	x := 521288629546311 ^ uint64(hi)
+	r.seedXcngXs(uint64(lo), x)
+	if x == 0 {
+		r.Carry++
+	}
+	r.warmUp()
+}
+
+// Reset reinitializes r with seed exactly as Seed does, reusing r's Q
+// slice instead of allocating a new one as NewSuperKISS64 does.  It is
+// useful when cycling through many seeds in a loop.  Reset allocates Q only
+// if r does not already have one of length QSIZE64, as with a zero SK64.
+func (r *SK64) Reset(seed int64) {
+	if len(r.Q) != QSIZE64 {
+		r.Q = make([]uint64, QSIZE64)
+	}
+	r.Seed(seed)
+}
+
+// Wipe zeroes the entire state of r, including every element of Q and any
+// buffered bits, and marks r unseeded, so that state derived from secret
+// entropy does not linger in memory.  r must be reseeded before reuse;
+// otherwise the next Uint64 call seeds it with Seed(1), as for a zero SK64.
+func (r *SK64) Wipe() {
+	clear(r.Q)
+	clear(r.seed)
+	r.seed = nil
+	r.Carry, r.Xcng, r.Xs, r.Index = 0, 0, 0, 0
+	r.Bits, r.NBits, r.Drawn = 0, 0, 0
+	r.Seeded = false
+}
+
+// SeedArray added to C code by Ron Charlton on 2020-09-05.
+
+// SeedFromSlice provides a full range of repeatable initializations (Seed has
+// only 2^63-1 starting points with math/rand). Call SeedFromSlice with a
+// slice of one or more random uint64 numbers.
+// It is best to call SeedFromSlice with QSIZE64 random numbers, although
+// any number of values is acceptable. If len(s) > QSIZE64, only the first
+// QSIZE64 elements in s are used.  SeedFromSlice records a copy of the
+// elements used for SeedSlice.
+func (r *SK64) SeedFromSlice(s []uint64) {
+	r.seedFromSlice(s)
+	// Q[0] is finally mixed with s[QSIZE64], so QSIZE64+1 elements are used.
+	r.seed = append([]uint64{}, s[:min(len(s), QSIZE64+1)]...)
+}
+
+// seedFromSlice is SeedFromSlice without recording s for SeedSlice, for
+// methods that build their own seed slice.
+func (r *SK64) seedFromSlice(s []uint64) {
+	var i, j, n uint64
+	count := uint64(len(s))
+	r.Seeded = true
+	r.seed = nil
+	r.Bits, r.NBits, r.Drawn = 0, 0, 0
+
+	r.Xcng = 12367890123456
+	r.Xs = 521288629546311
+	r.Carry = 36243678541
+	r.Index = QSIZE64
+
+	if count > 0 {
+		r.Xs = xs(r.Xs)
+		r.Q[0] = s[j] + r.cng() + r.Xs
+		j++
+		for i = 1; i < QSIZE64; i++ {
+			n = r.Q[i-1]
+			j %= count // in this location in case count is 1
+			r.Q[i] = s[j] + r.cng() + xs(n) + i
+			j++
+		}
+		r.Q[0] = s[j%count] + r.cng() + xs(r.Q[i-1]) + i
+	} else {
+		for i = 0; i < QSIZE64; i++ {
+			r.Xs = xs(r.Xs)
+			r.Q[i] = r.cng() + r.Xs
+		}
+	}
+	r.nonzeroXs()
+	r.warmUp()
+}
+
+// SeedSlice returns a copy of the seed slice r was last initialized from
+// by SeedFromSlice, SeedFromBytes or NewSuperKISS64FromSlice, and true;
+// calling SeedFromSlice with the copy on another generator reproduces r's
+// sequence from the start.  If r was last seeded another way, including
+// by methods such as SeedMix and SeedFromReader that build their own seed
+// slice, or was loaded from saved state or wiped, SeedSlice returns nil
+// and false.  The recorded slice is not part of the saved state.
+func (r *SK64) SeedSlice() ([]uint64, bool) {
+	if r.seed == nil {
+		return nil, false
+	}
+	return append([]uint64{}, r.seed...), true
+}
 
 // SeedArray is provided for compatibility with older versions.  It is
 // deprecated.  Use SeedFromSlice instead in new code.
@@ -378,6 +1311,94 @@ func (r *SK64) SeedArray(array []uint64) {
 	r.SeedFromSlice(array)
 }
 
+// SeedFromBytes initializes r from the bytes in b, for example entropy
+// from an external key derivation function.  b is read as little-endian
+// uint64 values, zero-padded to a multiple of 8 bytes, and passed to
+// SeedFromSlice, so the same bytes always yield the same sequence on any
+// platform.  If b is empty, r gets the default initialization that
+// SeedFromSlice gives an empty slice.
+func (r *SK64) SeedFromBytes(b []byte) {
+	r.SeedFromSlice(bytesToSeed(b))
+}
+
+// bytesToSeed returns b as little-endian uint64 values, zero-padded to a
+// multiple of 8 bytes, as SeedFromBytes uses them.
+func bytesToSeed(b []byte) []uint64 {
+	seed := make([]uint64, (len(b)+7)/8)
+	for i := range seed {
+		var word [8]byte
+		copy(word[:], b[i*8:])
+		seed[i] = binary.LittleEndian.Uint64(word[:])
+	}
+	return seed
+}
+
+// SeedFromString initializes r from string s, for example a
+// human-readable experiment name.  The SHA-256 hash of s is used as
+// SeedFromBytes uses its bytes, so the same string always yields the same
+// sequence, on any platform.
+func (r *SK64) SeedFromString(s string) {
+	sum := sha256.Sum256([]byte(s))
+	r.seedFromSlice(bytesToSeed(sum[:]))
+}
+
+// SeedFromHash initializes r from the current 64-bit digest of h, for
+// pipelines that already hash their input.  h.Sum64() is expanded by
+// SplitMix64 into a QSIZE64-element slice for SeedFromSlice, so hashes
+// with the same digest always yield the same sequence.  h is not modified.
+func (r *SK64) SeedFromHash(h hash.Hash64) {
+	x := h.Sum64()
+	s := make([]uint64, QSIZE64)
+	for i := range s {
+		s[i] = splitmix64(&x)
+	}
+	r.seedFromSlice(s)
+}
+
+// SeedMix initializes r from a fixed seed, such as an experiment's logged
+// seed, combined with any number of extra values, such as runtime entropy.
+// The fixed seed and each extra value in order are folded through the
+// SplitMix64 mixing function, whose final state is expanded by SplitMix64
+// into a QSIZE64-element slice for SeedFromSlice.  The same arguments in
+// the same order always yield the same sequence; with no extra values the
+// sequence depends only on fixed.
+func (r *SK64) SeedMix(fixed int64, extra ...uint64) {
+	x := uint64(fixed)
+	h := splitmix64(&x)
+	for _, e := range extra {
+		x = h ^ e
+		h = splitmix64(&x)
+	}
+	x = h
+	s := make([]uint64, QSIZE64)
+	for i := range s {
+		s[i] = splitmix64(&x)
+	}
+	r.seedFromSlice(s)
+}
+
+// SeedFromReader initializes r from up to QSIZE64*8 bytes read from src,
+// for example /dev/urandom or a file of recorded entropy, as
+// SeedFromBytes does.  If src ends early but at least 8 bytes were read,
+// the bytes read are used.  Otherwise an error is returned and r is left
+// unchanged.
+func (r *SK64) SeedFromReader(src io.Reader) error {
+	b := make([]byte, QSIZE64*8)
+	n, err := io.ReadFull(src, b)
+	if err == io.ErrUnexpectedEOF && n >= 8 {
+		err = nil
+	}
+	if err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			return fmt.Errorf("SuperKISS64:SeedFromReader read %d bytes; "+
+				"want at least 8: %w", n, err)
+		}
+		return err
+	}
+	r.seedFromSlice(bytesToSeed(b[:n]))
+	return nil
+}
+
 // SeedFromCrypto does NOT make r cryptographically secure.
 // It initializes r with random numbers from crypto/rand.
 // Again, SeedFromCrypto does NOT make r cryptographically secure.
@@ -385,12 +1406,12 @@ func (r *SK64) SeedArray(array []uint64) {
 // SuperKISS64 sequences.
 func (r *SK64) SeedFromCrypto() {
 	r.Seeded = true
+	r.seed = nil
+	r.Bits, r.NBits, r.Drawn = 0, 0, 0
 	cr := NewCryptoSource()
 	r.Xcng = cr.Uint64()
 	r.Xs = cr.Uint64()
-	for r.Xs == 0 {
-		r.Xs = cr.Uint64()
-	}
+	r.nonzeroXs()
 	r.Carry = cr.Uint64()
 	r.Index = QSIZE64
 	for i := 0; i < QSIZE64; i++ {
@@ -398,17 +1419,34 @@ func (r *SK64) SeedFromCrypto() {
 	}
 }
 
+// nonzeroXs replaces a zero r.Xs, which would stay zero forever and drop
+// the Xor-Shift component from every output, with Seed's starting value.
+// Seed and SeedFromSlice cannot produce a zero Xs because XorShift never
+// maps a nonzero value to zero, but each seeding path calls nonzeroXs so
+// that the guarantee does not depend on that reasoning.
+func (r *SK64) nonzeroXs() {
+	if r.Xs == 0 {
+		r.Xs = 521288629546311
+	}
+}
+
 // Ported by RC from GM C code:
 
 func (r *SK64) refill() uint64 {
-	var z, h uint64
-
-	for i := 0; i < QSIZE64; i++ {
-		h = r.Carry & 1
-		z = ((r.Q[i] << 41) >> 1) + ((r.Q[i] << 39) >> 1) + (r.Carry >> 1)
-		r.Carry = (r.Q[i] >> 23) + (r.Q[i] >> 25) + (z >> 63)
-		r.Q[i] = ^((z << 1) + h)
+	// Keeping the carry in a local and ranging over a fixed-length slice
+	// lets the compiler hold it in a register, load each Q entry once and
+	// drop the bounds checks.  BenchmarkRefill measured about 37 us per
+	// refill against 47 us before, some 20% faster.  Each step needs the
+	// previous carry, so unrolling or reordering the shifts gains nothing.
+	q := r.Q[:QSIZE64]
+	carry := r.Carry
+	for i, t := range q {
+		h := carry & 1
+		z := ((t << 41) >> 1) + ((t << 39) >> 1) + (carry >> 1)
+		carry = (t >> 23) + (t >> 25) + (z >> 63)
+		q[i] = ^((z << 1) + h)
 	}
+	r.Carry = carry
 
 	r.Index = 1
 
@@ -417,7 +1455,7 @@ func (r *SK64) refill() uint64 {
 
 // Uint64 returns a 64-bit, uniformly distributed pseudorandom number
 // in the range [0,2^64) from SuperKISS64.  This method implements the
-// math/rand.Source64 interface.
+// math/rand.Source64 and math/rand/v2.Source interfaces.
 func (r *SK64) Uint64() (result uint64) {
 	if !r.Seeded {
 		r.Seed(1)
@@ -431,11 +1469,135 @@ func (r *SK64) Uint64() (result uint64) {
 	}
 	r.Xs = xs(r.Xs)
 	result += r.cng() + r.Xs
+	r.Drawn++
 	return
 }
 
+// Count returns the number of Uint64 values r has generated since it was
+// last seeded or ResetCount was called, including those generated for
+// other methods such as Float64 and Read, and those skipped by Skip.
+// Seeding resets the count to 0 after any warm-up.  The count is part of
+// the saved state, and wraps around after 2^64 values.
+func (r *SK64) Count() uint64 {
+	return r.Drawn
+}
+
+// ResetCount sets the count returned by Count to 0 without changing the
+// sequence r generates.
+func (r *SK64) ResetCount() {
+	r.Drawn = 0
+}
+
+// Position returns r.Index, the index in Q of the value the next Uint64
+// call will use.  Position is QSIZE64 when Q must be refilled first, as
+// right after seeding, and 1 just after a refill.  It is for debugging
+// alignment with refill boundaries.
+func (r *SK64) Position() uint64 {
+	return r.Index
+}
+
+// Peek returns the value the next call of Uint64 will return, without
+// changing the state of r.  Peek does not mutate r even when a refill of
+// Q is due, since it computes only the first refilled element.  If r has
+// not been seeded, Peek reports the first value after Seed(1) by way of a
+// temporary clone.
+func (r *SK64) Peek() uint64 {
+	if !r.Seeded {
+		return r.Clone().Uint64()
+	}
+	var q uint64
+	if r.Index < QSIZE64 {
+		q = r.Q[r.Index]
+	} else {
+		// the first step of refill
+		h := r.Carry & 1
+		z := ((r.Q[0] << 41) >> 1) + ((r.Q[0] << 39) >> 1) + (r.Carry >> 1)
+		q = ^((z << 1) + h)
+	}
+	return q + (6906969069*r.Xcng + 123) + xs(r.Xs)
+}
+
+// FillUint64 fills dst with values exactly as len(dst) sequential calls of
+// Uint64 would, but walks runs of Q in bulk between refills, amortizing
+// the per-call overhead.
+func (r *SK64) FillUint64(dst []uint64) {
+	if len(dst) == 0 {
+		return
+	}
+	if !r.Seeded {
+		r.Seed(1)
+	}
+	r.Drawn += uint64(len(dst))
+	for len(dst) > 0 {
+		if r.Index >= QSIZE64 {
+			r.refill()
+			r.Index = 0 // walk Q from its refilled first value
+		}
+		q := r.Q[r.Index:]
+		if len(q) > len(dst) {
+			q = q[:len(dst)]
+		}
+		x, c := r.Xs, r.Xcng
+		for i, v := range q {
+			x = xs(x)
+			c = 6906969069*c + 123
+			dst[i] = v + c + x
+		}
+		r.Xs, r.Xcng = x, c
+		r.Index += uint64(len(q))
+		dst = dst[len(q):]
+	}
+}
+
 // RC code:
 
+// takeBits returns the next n (1 to 63) buffered bits of r, refilling the
+// buffer from Uint64 when it holds fewer than n bits.  Any bits left in
+// the buffer when it is refilled are discarded.
+func (r *SK64) takeBits(n uint64) uint64 {
+	if r.NBits < n {
+		r.Bits = r.Uint64()
+		r.NBits = 64
+	}
+	v := r.Bits & (1<<n - 1)
+	r.Bits >>= n
+	r.NBits -= n
+	return v
+}
+
+// Bool returns a pseudorandom bool from SuperKISS64, true or false with
+// equal probability.  Each call uses one bit of a buffered Uint64 value, so
+// 64 calls of Bool use one call of Uint64.  The buffered bits are part of
+// r's state (fields Bits and NBits), so they are saved, loaded, cloned and
+// compared with the rest of the state and cleared by seeding.
+func (r *SK64) Bool() bool {
+	return r.takeBits(1) == 1
+}
+
+// Uint32 returns a 32-bit, uniformly distributed pseudorandom number in
+// the range [0,2^32) from SuperKISS64.  Successive calls return the low
+// then the high half of one buffered Uint64 value, so one call of Uint64
+// feeds two calls of Uint32.  The buffer is shared with Bool and Int31; see
+// Bool.
+func (r *SK64) Uint32() uint32 {
+	return uint32(r.takeBits(32))
+}
+
+// Int31 returns a uniformly distributed pseudorandom number in the range
+// [0,2^31) from SuperKISS64, using the buffered bits of Uint32.
+func (r *SK64) Int31() int32 {
+	return int32(r.takeBits(32) >> 1)
+}
+
+// ReadByte returns one pseudorandom byte from SuperKISS64 and a nil error.
+// Successive calls return the bytes of one buffered Uint64 value in
+// little-endian order, so eight calls of ReadByte use one call of Uint64.
+// The buffer is shared with Bool, Uint32 and Int31; see Bool.  This method
+// implements the io.ByteReader interface.
+func (r *SK64) ReadByte() (byte, error) {
+	return byte(r.takeBits(8)), nil
+}
+
 // Int63 returns a uniformly distributed pseudorandom number in the range
 // [0,2^63) from SuperKISS64.  This method implements the math/rand.Source
 // interface.
@@ -443,42 +1605,1319 @@ func (r *SK64) Int63() int64 {
 	return int64(r.Uint64() >> 1)
 }
 
-// Float64 returns a uniformly-distributed, pseudorandom float64 value in
-// range [0.0,1.0) from SuperKISS64. It assumes IEEE 754-1985 or later
-// floating point.
-func (r *SK64) Float64() float64 {
-	// See the table in
-	// https://en.wikipedia.org/wiki/IEEE_754-1985#Range_and_precision,
-	// Double precision, Actual Exponent of 0.
-	n := (r.Uint64() >> 2) | 0x3FF0000000000000
-	return math.Float64frombits(n) - 1.0
+// Int returns a non-negative pseudorandom int from SuperKISS64, as
+// math/rand.Rand.Int does: 63 bits on 64-bit platforms and 31 bits on
+// 32-bit platforms.
+func (r *SK64) Int() int {
+	return int(uint(r.Uint64()) << 1 >> 1)
 }
 
-// Float32 returns a uniformly-distributed, pseudorandom float32 value in
-// range [0.0,1.0) from SuperKISS64. It assumes IEEE 754-1985 or later
-// floating point.
-func (r *SK64) Float32() float32 {
-	// See the table in
-	// https://en.wikipedia.org/wiki/IEEE_754-1985#Range_and_precision,
-	// Single precision, Actual Exponent of 0.
-	n := uint32(r.Uint64())>>2 | 0x3F800000
-	return math.Float32frombits(n) - 1.0
+// Int63n returns a uniformly distributed pseudorandom number in the range
+// [0,n) from SuperKISS64.  Rejection sampling is used, so the result has
+// no modulo bias.  It panics if n <= 0.
+func (r *SK64) Int63n(n int64) int64 {
+	if n <= 0 {
+		panic("SuperKISS64:Int63n called with n <= 0")
+	}
+	if n&(n-1) == 0 { // n is a power of 2
+		return r.Int63() & (n - 1)
+	}
+	max := int64((1 << 63) - 1 - (1<<63)%uint64(n))
+	v := r.Int63()
+	for v > max {
+		v = r.Int63()
+	}
+	return v % n
 }
 
-// Read fills p with pseudorandom bytes from SuperKISS64.  This method
-// implements the io.Reader interface.  The returned length n is always
-// len(p) and err is always nil.
-func (r *SK64) Read(p []byte) (n int, err error) {
-	for ; n+8 <= len(p); n += 8 {
-		binary.LittleEndian.PutUint64(p[n:], r.Uint64())
+// Intn returns a uniformly distributed pseudorandom number in the range
+// [0,n) from SuperKISS64.  Like Int63n it has no modulo bias.  It panics
+// if n <= 0.
+func (r *SK64) Intn(n int) int {
+	if n <= 0 {
+		panic("SuperKISS64:Intn called with n <= 0")
 	}
-	if n < len(p) {
-		val := r.Uint64()
-		for n < len(p) {
-			p[n] = byte(val)
-			val >>= 8
-			n++
-		}
+	return int(r.Int63n(int64(n)))
+}
+
+// Uint64n returns a uniformly distributed pseudorandom number in the range
+// [0,n) from SuperKISS64.  It uses Daniel Lemire's multiply-and-shift
+// method, "Fast Random Integer Generation in an Interval", ACM Transactions
+// on Modeling and Computer Simulation 29(1), 2019, which needs a division
+// and a retry only in the rare case of a draw falling in the rejection
+// window, so the result has no modulo bias.  It panics if n == 0.
+func (r *SK64) Uint64n(n uint64) uint64 {
+	if n == 0 {
+		panic("SuperKISS64:Uint64n called with n == 0")
+	}
+	hi, lo := bits.Mul64(r.Uint64(), n)
+	if lo < n {
+		thresh := -n % n // (2^64 - n) % n
+		for lo < thresh {
+			hi, lo = bits.Mul64(r.Uint64(), n)
+		}
+	}
+	return hi
+}
+
+// Shuffle pseudo-randomizes the order of elements using SuperKISS64 and
+// the Fisher-Yates algorithm, with the same semantics as math/rand's
+// Shuffle.  n is the number of elements.  Shuffle panics if n < 0.
+// swap swaps the elements with indexes i and j.
+func (r *SK64) Shuffle(n int, swap func(i, j int)) {
+	if n < 0 {
+		panic("SuperKISS64:Shuffle called with n < 0")
+	}
+	for i := n - 1; i > 0; i-- {
+		j := int(r.Uint64n(uint64(i + 1)))
+		swap(i, j)
+	}
+}
+
+// ShuffleSeed pseudo-randomizes the order of n elements as Shuffle does,
+// using a SuperKISS64 generator seeded with seed, so the same seed always
+// yields the same order.  It is for reproducible test fixtures.  swap
+// swaps the elements with indexes i and j.  ShuffleSeed panics if n < 0.
+func ShuffleSeed(seed int64, n int, swap func(i, j int)) {
+	NewSuperKISS64(seed).Shuffle(n, swap)
+}
+
+// Perm returns, as a slice of n ints, a pseudo-random permutation of the
+// integers in the range [0,n) from SuperKISS64, with the same semantics as
+// math/rand's Perm.  Perm(0) returns an empty, non-nil slice.  Perm panics
+// if n < 0.
+func (r *SK64) Perm(n int) []int {
+	if n < 0 {
+		panic("SuperKISS64:Perm called with n < 0")
+	}
+	m := make([]int, n)
+	for i := 0; i < n; i++ {
+		j := int(r.Uint64n(uint64(i + 1)))
+		m[i] = m[j]
+		m[j] = i
+	}
+	return m
+}
+
+// Int64Range returns a uniformly distributed pseudorandom number in the
+// inclusive range [min,max] from SuperKISS64.  The full int64 range
+// [math.MinInt64,math.MaxInt64] is allowed.  Like Uint64n it has no modulo
+// bias.  It panics if min > max.
+func (r *SK64) Int64Range(min, max int64) int64 {
+	if min > max {
+		panic("SuperKISS64:Int64Range called with min > max")
+	}
+	span := uint64(max) - uint64(min) // cannot overflow in uint64
+	if span == math.MaxUint64 {
+		return int64(r.Uint64())
+	}
+	return int64(uint64(min) + r.Uint64n(span+1))
+}
+
+// IntRange returns a uniformly distributed pseudorandom number in the
+// inclusive range [min,max] from SuperKISS64.  It panics if min > max.
+func (r *SK64) IntRange(min, max int) int {
+	if min > max {
+		panic("SuperKISS64:IntRange called with min > max")
+	}
+	return int(r.Int64Range(int64(min), int64(max)))
+}
+
+// Choice returns a uniformly chosen pseudorandom element of s, using
+// SuperKISS64 generator r.  Like Uint64n it has no modulo bias, however
+// long s is.  Choice panics if s is empty.
+func Choice[T any](r *SK64, s []T) T {
+	if len(s) == 0 {
+		panic("SuperKISS64:Choice called with empty s")
+	}
+	return s[r.Uint64n(uint64(len(s)))]
+}
+
+// WeightedIndex returns a pseudorandom index i into weights from
+// SuperKISS64, chosen with probability weights[i] divided by the sum of
+// weights.  It uses a cumulative sum and a binary search over a single
+// Float64 draw.  Indexes with zero weight are never chosen.  WeightedIndex
+// panics if weights is empty, if any weight is negative, NaN or infinite,
+// or if all weights are zero.
+func (r *SK64) WeightedIndex(weights []float64) int {
+	cum := make([]float64, len(weights))
+	total := 0.0
+	for i, w := range weights {
+		if !(w >= 0) || math.IsInf(w, 1) {
+			panic("SuperKISS64:WeightedIndex called with a negative, NaN " +
+				"or infinite weight")
+		}
+		total += w
+		cum[i] = total
+	}
+	if !(total > 0) || math.IsInf(total, 1) {
+		panic("SuperKISS64:WeightedIndex called with weights that sum " +
+			"to zero or overflow")
+	}
+	x := r.Float64() * total
+	i := sort.Search(len(cum), func(i int) bool { return cum[i] > x })
+	if i == len(cum) { // x rounded up to total
+		i--
+	}
+	for weights[i] == 0 { // only reachable after rounding
+		i--
+	}
+	return i
+}
+
+// AliasTable samples indexes from a fixed categorical distribution in
+// constant time per draw using Walker's alias method, as built by Michael
+// Vose's algorithm.  It suits hot loops that draw repeatedly from the same
+// weights, where WeightedIndex would redo its cumulative sum every call.
+// An AliasTable is not modified by Sample, so one table can be shared by
+// goroutines that each use their own generator.
+type AliasTable struct {
+	prob  []float64 // probability of keeping column i rather than its alias
+	alias []int
+}
+
+// NewAliasTable returns an AliasTable that chooses index i with probability
+// weights[i] divided by the sum of weights.  Indexes with zero weight are
+// never chosen.  NewAliasTable panics if weights is empty, if any weight
+// is negative, NaN or infinite, or if all weights are zero.
+func NewAliasTable(weights []float64) *AliasTable {
+	n := len(weights)
+	total := 0.0
+	for _, w := range weights {
+		if !(w >= 0) || math.IsInf(w, 1) {
+			panic("SuperKISS64:NewAliasTable called with a negative, NaN " +
+				"or infinite weight")
+		}
+		total += w
+	}
+	if !(total > 0) || math.IsInf(total, 1) {
+		panic("SuperKISS64:NewAliasTable called with weights that sum " +
+			"to zero or overflow")
+	}
+	a := &AliasTable{prob: make([]float64, n), alias: make([]int, n)}
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, w := range weights {
+		scaled[i] = w / total * float64(n)
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		a.prob[s] = scaled[s]
+		a.alias[s] = l
+		scaled[l] -= 1 - scaled[s]
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+	// Whatever remains is 1 but for rounding error.
+	for _, i := range append(small, large...) {
+		a.prob[i] = 1
+		a.alias[i] = i
+	}
+	return a
+}
+
+// Sample returns a pseudorandom index from SuperKISS64 generator r,
+// distributed as the weights given to NewAliasTable.  It uses one bounded
+// Uint64 draw and one Float64 draw.
+func (a *AliasTable) Sample(r *SK64) int {
+	i := int(r.Uint64n(uint64(len(a.prob))))
+	if r.Float64() < a.prob[i] {
+		return i
+	}
+	return a.alias[i]
+}
+
+// Sample returns k distinct elements of s chosen without replacement, in
+// pseudorandom order, using SuperKISS64 generator r.  It performs a
+// partial Fisher-Yates shuffle that records only the positions it
+// disturbs, so s is not modified and the extra space used is O(k).
+// Sample panics if k < 0 or k > len(s).
+func Sample[T any](r *SK64, s []T, k int) []T {
+	if k < 0 || k > len(s) {
+		panic("SuperKISS64:Sample called with k < 0 or k > len(s)")
+	}
+	out := make([]T, k)
+	moved := make(map[int]int, k) // position -> index in s now there
+	at := func(i int) int {
+		if j, ok := moved[i]; ok {
+			return j
+		}
+		return i
+	}
+	for i := range out {
+		j := i + int(r.Uint64n(uint64(len(s)-i)))
+		vi, vj := at(i), at(j)
+		moved[j] = vi
+		out[i] = s[vj]
+	}
+	return out
+}
+
+// ReservoirSample returns up to n items chosen uniformly from a stream of
+// unknown length, using SuperKISS64 generator r and Jeffrey Vitter's
+// Algorithm R.  Items are pulled from next until it returns false; each
+// item has the same probability of ending up in the result.  Only the n
+// items of the reservoir are held in memory.  Fewer than n items are
+// returned if the stream has fewer than n items.  ReservoirSample panics
+// if n < 0.
+func ReservoirSample[T any](r *SK64, n int, next func() (T, bool)) []T {
+	if n < 0 {
+		panic("SuperKISS64:ReservoirSample called with n < 0")
+	}
+	res := make([]T, 0, n)
+	for seen := uint64(0); ; seen++ {
+		item, ok := next()
+		if !ok {
+			return res
+		}
+		if len(res) < n {
+			res = append(res, item)
+		} else if j := r.Uint64n(seen + 1); j < uint64(n) {
+			res[j] = item
+		}
+	}
+}
+
+// DrawRemove removes a uniformly chosen pseudorandom element from *s and
+// returns it, using SuperKISS64 generator r, as when dealing cards from a
+// deck.  The chosen element is swapped with the last one and *s is
+// shortened by one, so the order of the remaining elements changes.
+// DrawRemove panics if *s is empty.
+func DrawRemove[T any](r *SK64, s *[]T) T {
+	n := len(*s)
+	if n == 0 {
+		panic("SuperKISS64:DrawRemove called with empty slice")
+	}
+	d := *s
+	i := r.Intn(n)
+	d[i], d[n-1] = d[n-1], d[i]
+	x := d[n-1]
+	var zero T
+	d[n-1] = zero // drop the reference for the garbage collector
+	*s = d[:n-1]
+	return x
+}
+
+// Fill fills dst with uniformly distributed pseudorandom values of its
+// fixed-width integer element type from SuperKISS64 generator r.  Each
+// Uint64 value supplies 8/size elements of size bytes each, taken from its
+// least significant bits first, the same order in which Read packs bytes;
+// bits left over at the end of dst are discarded.
+func Fill[T ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+	~int8 | ~int16 | ~int32 | ~int64](r *SK64, dst []T) {
+	width := 8 * unsafe.Sizeof(*new(T))
+	for i := 0; i < len(dst); {
+		v := r.Uint64()
+		for k := uintptr(0); k < 64 && i < len(dst); k += width {
+			dst[i] = T(v)
+			v >>= width
+			i++
+		}
+	}
+}
+
+// Float64 returns a uniformly-distributed, pseudorandom float64 value in
+// range [0.0,1.0) from SuperKISS64. It assumes IEEE 754-1985 or later
+// floating point.
+func (r *SK64) Float64() float64 {
+	return toFloat64(r.Uint64())
+}
+
+// toFloat64 converts v to the float64 in [0.0,1.0) that Float64 returns
+// for it.
+func toFloat64(v uint64) float64 {
+	// See the table in
+	// https://en.wikipedia.org/wiki/IEEE_754-1985#Range_and_precision,
+	// Double precision, Actual Exponent of 0.
+	n := (v >> 2) | 0x3FF0000000000000
+	return math.Float64frombits(n) - 1.0
+}
+
+// Float64Full returns a uniformly-distributed, pseudorandom float64 value
+// in range [0.0,1.0) from SuperKISS64, using the top 53 bits of one Uint64
+// so that all 2^53 multiples of 2^-53 in the range are possible outputs.
+// Float64 is kept unchanged so existing sequences remain reproducible.
+func (r *SK64) Float64Full() float64 {
+	return float64(r.Uint64()>>11) * 0x1p-53
+}
+
+// Float64Open returns a uniformly-distributed, pseudorandom float64 value
+// in the open range (0.0,1.0) from SuperKISS64, so that 1/x, 1/(1-x),
+// log(x) and log(1-x) are always finite.  It takes the top 52 bits of one
+// Uint64 as an integer k and returns (k+0.5)*2^-52, the midpoint of one of
+// 2^52 equal subintervals of [0,1); results therefore lie in
+// [2^-53, 1-2^-53], and both are exactly representable.
+func (r *SK64) Float64Open() float64 {
+	return (float64(r.Uint64()>>12) + 0.5) * 0x1p-52
+}
+
+// AntitheticFloat64 returns an antithetic pair from one Float64 draw: u,
+// uniformly distributed in [0.0,1.0), and its complement v = 1-u, in
+// (0.0,1.0].  Since Float64 returns multiples of 2^-52, v is exact.  u and
+// v are perfectly negatively correlated, so for a monotone function f the
+// estimates f(u) and f(v) are negatively correlated too, and averaging
+// them has lower variance than averaging two independent draws.
+func (r *SK64) AntitheticFloat64() (u, v float64) {
+	u = r.Float64()
+	return u, 1 - u
+}
+
+// FillFloat64 fills dst with values exactly as len(dst) sequential calls of
+// Float64 would, drawing the underlying Uint64 values in bulk with
+// FillUint64.
+func (r *SK64) FillFloat64(dst []float64) {
+	var buf [256]uint64
+	for len(dst) > 0 {
+		u := buf[:min(len(buf), len(dst))]
+		r.FillUint64(u)
+		for i, v := range u {
+			dst[i] = toFloat64(v)
+		}
+		dst = dst[len(u):]
+	}
+}
+
+// Float32 returns a uniformly-distributed, pseudorandom float32 value in
+// range [0.0,1.0) from SuperKISS64. It assumes IEEE 754-1985 or later
+// floating point.
+func (r *SK64) Float32() float32 {
+	// See the table in
+	// https://en.wikipedia.org/wiki/IEEE_754-1985#Range_and_precision,
+	// Single precision, Actual Exponent of 0.
+	n := uint32(r.Uint64())>>2 | 0x3F800000
+	return math.Float32frombits(n) - 1.0
+}
+
+// Float64Range returns a uniformly-distributed, pseudorandom float64 value
+// in range [min,max) from SuperKISS64.  If min == max, min is returned.
+// It panics if min > max or if either bound is NaN.  Spans too large to
+// represent, such as [-math.MaxFloat64,math.MaxFloat64), are handled
+// without overflow, and rounding never yields max.
+func (r *SK64) Float64Range(min, max float64) float64 {
+	if !(min <= max) {
+		panic("SuperKISS64:Float64Range called with min > max or NaN")
+	}
+	if min == max {
+		return min
+	}
+	u := r.Float64()
+	var x float64
+	if span := max - min; !math.IsInf(span, 0) {
+		x = min + span*u
+	} else {
+		x = min*(1-u) + max*u // each term is finite
+	}
+	if x >= max {
+		x = math.Nextafter(max, min)
+	}
+	return x
+}
+
+// Ziggurat tables for NormFloat64 and ExpFloat64, after George Marsaglia
+// and Wai Wan Tsang, "The Ziggurat Method for Generating Random Variables",
+// Journal of Statistical Software 5(8), 2000.  zigNormR and zigExpR are the
+// starts of the tails; zigNormV and zigExpV are the areas of each of the
+// 128 normal and 256 exponential layers.
+const (
+	zigNormR = 3.442619855899
+	zigNormV = 9.91256303526217e-3
+	zigExpR  = 7.697117470131487
+	zigExpV  = 3.949659822581572e-3
+)
+
+var (
+	kn     [128]uint32
+	wn, fn [128]float64
+	ke     [256]uint32
+	we, fe [256]float64
+)
+
+func init() {
+	// Ported by RC from GM's zigset C code.
+	const m1 = 2147483648.0
+	dn, tn := zigNormR, zigNormR
+	q := zigNormV / math.Exp(-0.5*dn*dn)
+	kn[0] = uint32((dn / q) * m1)
+	kn[1] = 0
+	wn[0] = q / m1
+	wn[127] = dn / m1
+	fn[0] = 1.0
+	fn[127] = math.Exp(-0.5 * dn * dn)
+	for i := 126; i >= 1; i-- {
+		dn = math.Sqrt(-2.0 * math.Log(zigNormV/dn+math.Exp(-0.5*dn*dn)))
+		kn[i+1] = uint32((dn / tn) * m1)
+		tn = dn
+		fn[i] = math.Exp(-0.5 * dn * dn)
+		wn[i] = dn / m1
+	}
+
+	const m2 = 4294967296.0
+	de, te := zigExpR, zigExpR
+	q = zigExpV / math.Exp(-de)
+	ke[0] = uint32((de / q) * m2)
+	ke[1] = 0
+	we[0] = q / m2
+	we[255] = de / m2
+	fe[0] = 1.0
+	fe[255] = math.Exp(-de)
+	for i := 254; i >= 1; i-- {
+		de = -math.Log(zigExpV/de + math.Exp(-de))
+		ke[i+1] = uint32((de / te) * m2)
+		te = de
+		fe[i] = math.Exp(-de)
+		we[i] = de / m2
+	}
+}
+
+// NormFloat64 returns a normally distributed float64 in the range
+// [-math.MaxFloat64, +math.MaxFloat64] with standard normal distribution
+// (mean 0, standard deviation 1) from SuperKISS64, using George Marsaglia's
+// ziggurat method.  To produce a different normal distribution, callers
+// can adjust the output using:
+//
+//	sample := mean + stddev*r.NormFloat64()
+func (r *SK64) NormFloat64() float64 {
+	for {
+		hz := int32(r.Uint64() >> 32)
+		iz := hz & 127
+		x := float64(hz) * wn[iz]
+		if absInt32(hz) < kn[iz] {
+			return x // fast path, taken about 99% of the time
+		}
+		if iz == 0 {
+			// sample from the tail beyond zigNormR
+			for {
+				x = -math.Log(r.Float64()) * (1.0 / zigNormR)
+				y := -math.Log(r.Float64())
+				if y+y >= x*x {
+					break
+				}
+			}
+			if hz > 0 {
+				return zigNormR + x
+			}
+			return -zigNormR - x
+		}
+		if fn[iz]+r.Float64()*(fn[iz-1]-fn[iz]) < math.Exp(-0.5*x*x) {
+			return x
+		}
+	}
+}
+
+func absInt32(i int32) uint32 {
+	if i < 0 {
+		return uint32(-i)
+	}
+	return uint32(i)
+}
+
+// ExpFloat64 returns an exponentially distributed float64 in the range
+// [0, +math.MaxFloat64] with an exponential distribution whose rate
+// parameter (lambda) is 1 and whose mean is 1/lambda (1) from SuperKISS64,
+// using George Marsaglia's ziggurat method.  To produce a distribution with
+// a different rate parameter, callers can adjust the output using:
+//
+//	sample := r.ExpFloat64() / desiredRateParameter
+func (r *SK64) ExpFloat64() float64 {
+	for {
+		jz := uint32(r.Uint64() >> 32)
+		iz := jz & 255
+		x := float64(jz) * we[iz]
+		if jz < ke[iz] {
+			return x // fast path, taken about 99% of the time
+		}
+		if iz == 0 {
+			// sample from the tail beyond zigExpR
+			return zigExpR - math.Log(r.Float64())
+		}
+		if fe[iz]+r.Float64()*(fe[iz-1]-fe[iz]) < math.Exp(-x) {
+			return x
+		}
+	}
+}
+
+// Gamma returns a Gamma-distributed float64 from SuperKISS64 with the
+// given shape (k) and scale (theta) parameters.  Its mean is shape*scale
+// and its variance is shape*scale*scale.  Gamma uses the method of George
+// Marsaglia and Wai Wan Tsang, boosted by a power of a uniform variate
+// when shape < 1.  Gamma panics if shape <= 0 or scale <= 0.
+func (r *SK64) Gamma(shape, scale float64) float64 {
+	if !(shape > 0) || !(scale > 0) {
+		panic("SuperKISS64:Gamma called with shape <= 0 or scale <= 0")
+	}
+	if shape < 1 {
+		// Gamma(a) = Gamma(a+1) * U^(1/a), with U in (0,1]
+		u := 1 - r.Float64()
+		return r.Gamma(shape+1, scale) * math.Pow(u, 1/shape)
+	}
+	d := shape - 1.0/3.0
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := r.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := 1 - r.Float64() // in (0,1] so math.Log(u) is finite
+		x2 := x * x
+		if u < 1-0.0331*x2*x2 || math.Log(u) < 0.5*x2+d*(1-v+math.Log(v)) {
+			return d * v * scale
+		}
+	}
+}
+
+// Poisson returns a Poisson-distributed int64 from SuperKISS64 with mean
+// and variance lambda.  Poisson uses Donald Knuth's multiplication method
+// when lambda < 10 and Wolfgang Hörmann's transformed rejection method
+// (PTRS) otherwise, so its cost does not grow with lambda.  Poisson
+// returns 0 if lambda is 0, and panics if lambda < 0 or is NaN.
+func (r *SK64) Poisson(lambda float64) int64 {
+	if !(lambda >= 0) {
+		panic("SuperKISS64:Poisson called with lambda < 0")
+	}
+	if lambda == 0 {
+		return 0
+	}
+	if lambda < 10 {
+		limit := math.Exp(-lambda)
+		k := int64(0)
+		for p := r.Float64(); p > limit; p *= r.Float64() {
+			k++
+		}
+		return k
+	}
+	slam := math.Sqrt(lambda)
+	loglam := math.Log(lambda)
+	b := 0.931 + 2.53*slam
+	a := -0.059 + 0.02483*b
+	invalpha := 1.1239 + 1.1328/(b-3.4)
+	vr := 0.9277 - 3.6224/(b-2)
+	for {
+		u := r.Float64() - 0.5
+		v := r.Float64()
+		us := 0.5 - math.Abs(u)
+		k := math.Floor((2*a/us+b)*u + lambda + 0.43)
+		if us >= 0.07 && v <= vr {
+			return int64(k)
+		}
+		if k < 0 || (us < 0.013 && v > us) {
+			continue
+		}
+		lg, _ := math.Lgamma(k + 1)
+		if math.Log(v)+math.Log(invalpha)-math.Log(a/(us*us)+b) <=
+			-lambda+k*loglam-lg {
+			return int64(k)
+		}
+	}
+}
+
+// Binomial returns a Binomial(n, p)-distributed int64 from SuperKISS64:
+// the number of successes in n independent trials that each succeed with
+// probability p.  Its mean is n*p and its variance is n*p*(1-p).  Binomial
+// uses inversion when n*min(p,1-p) <= 30 and otherwise the BTPE rejection
+// method of Voratas Kachitvichyanukul and Bruce Schmeiser, so its cost does
+// not grow with n.  Binomial panics if n < 0 or p is not in [0,1].
+func (r *SK64) Binomial(n int64, p float64) int64 {
+	if n < 0 || !(p >= 0 && p <= 1) {
+		panic("SuperKISS64:Binomial called with n < 0 or p outside [0,1]")
+	}
+	if n == 0 || p == 0 {
+		return 0
+	}
+	if p == 1 {
+		return n
+	}
+	pp := min(p, 1-p)
+	var y int64
+	if float64(n)*pp <= 30 {
+		y = r.binomialInversion(n, pp)
+	} else {
+		y = r.binomialBTPE(n, pp)
+	}
+	if p > 0.5 {
+		y = n - y
+	}
+	return y
+}
+
+// binomialInversion returns a Binomial(n, p) sample for p <= 0.5 and small
+// n*p by sequential search of the CDF.
+func (r *SK64) binomialInversion(n int64, p float64) int64 {
+	q := 1 - p
+	qn := math.Exp(float64(n) * math.Log(q))
+	np := float64(n) * p
+	bound := min(float64(n), np+10*math.Sqrt(np*q+1))
+	x := 0.0
+	px := qn
+	u := r.Float64()
+	for u > px {
+		x++
+		if x > bound {
+			// rounding error exhausted the CDF; start over
+			x = 0
+			px = qn
+			u = r.Float64()
+		} else {
+			u -= px
+			px = ((float64(n) - x + 1) * p * px) / (x * q)
+		}
+	}
+	return int64(x)
+}
+
+// binomialBTPE returns a Binomial(n, p) sample for p <= 0.5 and n*p > 30
+// using the BTPE algorithm, following NumPy's implementation.
+func (r *SK64) binomialBTPE(n int64, p float64) int64 {
+	fn := float64(n)
+	q := 1 - p
+	fm := fn*p + p
+	m := math.Floor(fm)
+	p1 := math.Floor(2.195*math.Sqrt(fn*p*q)-4.6*q) + 0.5
+	xm := m + 0.5
+	xl := xm - p1
+	xr := xm + p1
+	c := 0.134 + 20.5/(15.3+m)
+	a := (fm - xl) / (fm - xl*p)
+	laml := a * (1 + a/2)
+	a = (xr - fm) / (xr * q)
+	lamr := a * (1 + a/2)
+	p2 := p1 * (1 + 2*c)
+	p3 := p2 + c/laml
+	p4 := p3 + c/lamr
+	nrq := fn * p * q
+	for {
+		u := r.Float64() * p4
+		v := r.Float64()
+		var y float64
+		switch {
+		case u <= p1: // triangular region; accept immediately
+			return int64(math.Floor(xm - p1*v + u))
+		case u <= p2: // parallelograms
+			x := xl + (u-p1)/c
+			v = v*c + 1 - math.Abs(m-x+0.5)/p1
+			if v > 1 {
+				continue
+			}
+			y = math.Floor(x)
+		case u <= p3: // left exponential tail
+			y = math.Floor(xl + math.Log(v)/laml)
+			if y < 0 || v == 0 {
+				continue
+			}
+			v = v * (u - p2) * laml
+		default: // right exponential tail
+			y = math.Floor(xr - math.Log(v)/lamr)
+			if y > fn || v == 0 {
+				continue
+			}
+			v = v * (u - p3) * lamr
+		}
+		k := math.Abs(y - m)
+		if k <= 20 || k >= nrq/2-1 {
+			// explicit evaluation of f(y)/f(m)
+			s := p / q
+			a := s * (fn + 1)
+			f := 1.0
+			if m < y {
+				for i := m + 1; i <= y; i++ {
+					f *= a/i - s
+				}
+			} else if m > y {
+				for i := y + 1; i <= m; i++ {
+					f /= a/i - s
+				}
+			}
+			if v <= f {
+				return int64(y)
+			}
+			continue
+		}
+		// squeeze using upper and lower bounds on log(f(y))
+		rho := (k / nrq) * ((k*(k/3+0.625)+0.1666666666666)/nrq + 0.5)
+		t := -k * k / (2 * nrq)
+		logV := math.Log(v)
+		if logV < t-rho {
+			return int64(y)
+		}
+		if logV > t+rho {
+			continue
+		}
+		x1 := y + 1
+		f1 := m + 1
+		z := fn + 1 - m
+		w := fn - y + 1
+		x2, f2, z2, w2 := x1*x1, f1*f1, z*z, w*w
+		stirling := func(v, v2 float64) float64 {
+			return (13680 - (462-(132-(99-140/v2)/v2)/v2)/v2) / v / 166320
+		}
+		if logV <= xm*math.Log(f1/x1)+(fn-m+0.5)*math.Log(z/w)+
+			(y-m)*math.Log(w*p/(x1*q))+
+			stirling(f1, f2)+stirling(z, z2)+stirling(x1, x2)+stirling(w, w2) {
+			return int64(y)
+		}
+	}
+}
+
+// Geometric returns a geometrically distributed int64 from SuperKISS64:
+// the number of failures before the first success in independent trials
+// that each succeed with probability p.  Its mean is (1-p)/p.  Geometric
+// uses the closed-form inversion floor(log(U)/log(1-p)) with U in (0,1],
+// and returns math.MaxInt64 in the unlikely event that the result does not
+// fit in an int64.  Geometric panics if p is not in (0,1].
+func (r *SK64) Geometric(p float64) int64 {
+	if !(p > 0 && p <= 1) {
+		panic("SuperKISS64:Geometric called with p outside (0,1]")
+	}
+	u := 1 - r.Float64() // in (0,1] so math.Log(u) is finite
+	x := math.Floor(math.Log(u) / math.Log1p(-p))
+	if x >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(x)
+}
+
+// Triangular returns a float64 from SuperKISS64 with a triangular
+// distribution on [min,max] that peaks at mode, using inverse-CDF sampling.
+// Its mean is (min+mode+max)/3.  If min == max, min is returned.
+// Triangular panics unless min <= mode <= max.
+func (r *SK64) Triangular(min, mode, max float64) float64 {
+	if !(min <= mode && mode <= max) {
+		panic("SuperKISS64:Triangular called without min <= mode <= max")
+	}
+	if min == max {
+		return min
+	}
+	u := r.Float64()
+	span := max - min
+	var x float64
+	if u < (mode-min)/span {
+		x = min + math.Sqrt(u*span*(mode-min))
+	} else {
+		x = max - math.Sqrt((1-u)*span*(max-mode))
+	}
+	return math.Max(min, math.Min(x, max)) // guard against rounding
+}
+
+// Dirichlet returns a pseudorandom probability vector from SuperKISS64
+// with a Dirichlet(alpha) distribution: len(alpha) non-negative values
+// that sum to 1, whose ith element has mean alpha[i]/sum(alpha).  It draws
+// an independent Gamma(alpha[i], 1) value for each element and divides
+// each by their sum.  Dirichlet panics if alpha is empty or any element of
+// alpha is not positive.
+func (r *SK64) Dirichlet(alpha []float64) []float64 {
+	if len(alpha) == 0 {
+		panic("SuperKISS64:Dirichlet called with empty alpha")
+	}
+	for _, a := range alpha {
+		if !(a > 0) {
+			panic("SuperKISS64:Dirichlet called with alpha element <= 0")
+		}
+	}
+	x := make([]float64, len(alpha))
+	for {
+		sum := 0.0
+		for i, a := range alpha {
+			x[i] = r.Gamma(a, 1)
+			sum += x[i]
+		}
+		if sum > 0 { // all draws can underflow to 0 for tiny alpha
+			for i := range x {
+				x[i] /= sum
+			}
+			return x
+		}
+	}
+}
+
+// Read fills p with pseudorandom bytes from SuperKISS64.  This method
+// implements the io.Reader interface.  The returned length n is always
+// len(p) and err is always nil.
+func (r *SK64) Read(p []byte) (n int, err error) {
+	// Draw whole words in bulk runs of Q; the output is the same as
+	// calling Uint64 once per 8 bytes.
+	var buf [256]uint64
+	for n+8 <= len(p) {
+		u := buf[:min(len(buf), (len(p)-n)/8)]
+		r.FillUint64(u)
+		for _, v := range u {
+			binary.LittleEndian.PutUint64(p[n:], v)
+			n += 8
+		}
+	}
+	n += readWords(p[n:], r.Uint64)
+	return
+}
+
+// readWords fills p with bytes packed from successive values of next in
+// the order SK64.Read uses: each value little-endian, with a final partial
+// value supplying its least significant bytes.  It returns len(p).
+func readWords(p []byte, next func() uint64) (n int) {
+	for ; n+8 <= len(p); n += 8 {
+		binary.LittleEndian.PutUint64(p[n:], next())
+	}
+	if n < len(p) {
+		val := next()
+		for n < len(p) {
+			p[n] = byte(val)
+			val >>= 8
+			n++
+		}
+	}
+	return
+}
+
+// ReadBigEndian is like Read, but packs each Uint64 value into p in
+// big-endian (network) byte order.  The underlying Uint64 values are the
+// same as Read uses; only the byte order within each value differs.  A
+// final partial value supplies its most significant bytes.
+func (r *SK64) ReadBigEndian(p []byte) (n int, err error) {
+	for ; n+8 <= len(p); n += 8 {
+		binary.BigEndian.PutUint64(p[n:], r.Uint64())
+	}
+	if n < len(p) {
+		val := r.Uint64()
+		for n < len(p) {
+			p[n] = byte(val >> 56)
+			val <<= 8
+			n++
+		}
+	}
+	return
+}
+
+// Fill fills all of p with pseudorandom bytes from SuperKISS64, exactly as
+// Read would.  It is for call sites that want a buffer of randomness
+// without checking Read's n and err, which never indicate a short read.
+func (r *SK64) Fill(p []byte) {
+	r.Read(p)
+}
+
+// RandomString returns a string of n runes, each chosen uniformly and
+// without bias from the runes of charset, using pseudorandom numbers from
+// SuperKISS64.  A rune that appears more than once in charset is chosen
+// proportionately more often.  (The method name String is taken by the
+// fmt.Stringer implementation.)  RandomString panics if charset is empty
+// or n < 0.
+func (r *SK64) RandomString(n int, charset string) string {
+	if n < 0 {
+		panic("SuperKISS64:RandomString called with n < 0")
+	}
+	runes := []rune(charset)
+	if len(runes) == 0 {
+		panic("SuperKISS64:RandomString called with empty charset")
+	}
+	var sb strings.Builder
+	sb.Grow(n)
+	for i := 0; i < n; i++ {
+		sb.WriteRune(runes[r.Uint64n(uint64(len(runes)))])
+	}
+	return sb.String()
+}
+
+// alphaNumeric is the charset of AlphaNumeric.
+const alphaNumeric = "ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+	"abcdefghijklmnopqrstuvwxyz0123456789"
+
+// AlphaNumeric returns a string of n characters chosen uniformly from
+// [A-Za-z0-9] using pseudorandom numbers from SuperKISS64, for tokens and
+// IDs that need not be secret.  It panics if n < 0.
+func (r *SK64) AlphaNumeric(n int) string {
+	return r.RandomString(n, alphaNumeric)
+}
+
+// UUID returns a random version 4 UUID string, such as
+// "3f0c9a52-1b7e-4d2a-9c41-0e5b8f6a7d13", built from 16 pseudorandom bytes
+// from SuperKISS64 with the version and variant bits set per RFC 9562.
+// UUID is NOT cryptographically secure; use it only for identifiers that
+// need not be unguessable.
+func (r *SK64) UUID() string {
+	var u [16]byte
+	r.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // variant 10xx
+	return fmt.Sprintf("%x-%x-%x-%x-%x",
+		u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// LimitReader returns an io.Reader that reads at most n pseudorandom bytes
+// from r, then returns io.EOF.  The bytes are those Read would produce;
+// reading in smaller pieces may consume extra Uint64 values at piece
+// boundaries, since Read discards the unused bytes of a final partial
+// value.  It is handy with io.Copy, as in io.Copy(w, r.LimitReader(n)).
+func (r *SK64) LimitReader(n int64) io.Reader {
+	return io.LimitReader(r, n)
+}
+
+// ReadUntil fills p one pseudorandom byte at a time, as ReadByte produces
+// them, until stop returns true for a byte or p is full, and returns the
+// number of bytes written to p.  The byte that satisfied stop is included
+// in p and in the count.  ReadUntil suits rejection-style scanning without
+// a large buffer.
+func (r *SK64) ReadUntil(p []byte, stop func(b byte) bool) int {
+	for i := range p {
+		p[i], _ = r.ReadByte()
+		if stop(p[i]) {
+			return i + 1
+		}
+	}
+	return len(p)
+}
+
+// Values returns an infinite iterator over Uint64 values from r, for use
+// as in
+//
+//	for v := range r.Values() {
+//		if done(v) {
+//			break
+//		}
+//	}
+//
+// The loop must break or return, because the iterator never ends.
+func (r *SK64) Values() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		for yield(r.Uint64()) {
+		}
+	}
+}
+
+// Floats returns an infinite iterator over Float64 values from r.  As with
+// Values, a range loop over it must break or return.
+func (r *SK64) Floats() iter.Seq[float64] {
+	return func(yield func(float64) bool) {
+		for yield(r.Float64()) {
+		}
+	}
+}
+
+// Indices returns an iterator over count uniformly distributed
+// pseudorandom indexes in [0,n) from r, drawn with replacement as Intn
+// draws them, for use as in for i := range r.Indices(len(s), k).
+// Indices panics if n <= 0 or count < 0.
+func (r *SK64) Indices(n, count int) iter.Seq[int] {
+	if n <= 0 || count < 0 {
+		panic("SuperKISS64:Indices called with n <= 0 or count < 0")
+	}
+	return func(yield func(int) bool) {
+		for i := 0; i < count; i++ {
+			if !yield(r.Intn(n)) {
+				return
+			}
+		}
+	}
+}
+
+// Bytes returns a newly allocated slice of n pseudorandom bytes from
+// SuperKISS64, filled as by Read.  Bytes(0) returns an empty, non-nil
+// slice.  Bytes panics if n < 0.
+func (r *SK64) Bytes(n int) []byte {
+	if n < 0 {
+		panic("SuperKISS64:Bytes called with n < 0")
+	}
+	p := make([]byte, n)
+	r.Read(p)
+	return p
+}
+
+// WriteN writes n pseudorandom bytes from SuperKISS64 to w, the same bytes
+// a single Read of n bytes would produce, through one reusable 32 KB
+// buffer instead of an n-byte slice.  It returns the number of bytes
+// written and the first error encountered.  WriteN writes nothing if
+// n <= 0.
+func (r *SK64) WriteN(w io.Writer, n int64) (written int64, err error) {
+	if n <= 0 {
+		return
+	}
+	buf := make([]byte, min(n, 32*1024)) // a multiple of 8 bytes unless last
+	for written < n {
+		p := buf[:min(n-written, int64(len(buf)))]
+		r.Read(p)
+		var m int
+		m, err = w.Write(p)
+		written += int64(m)
+		if err != nil {
+			return
+		}
 	}
 	return
 }
+
+// Skip advances SuperKISS64 generator r as if Uint64 had been called n
+// times with the results discarded.  The congruential and Xor-Shift
+// components are jumped ahead in O(log n) steps, and Q is refilled once
+// per QSIZE64 values skipped instead of being walked one value at a time.
+// Skip is therefore much faster than calling Uint64 n times, although the
+// refills still make its cost grow with n/QSIZE64.
+func (r *SK64) Skip(n uint64) {
+	if n == 0 {
+		return
+	}
+	if !r.Seeded {
+		r.Seed(1)
+	}
+	r.Drawn += n
+	r.Xcng = cngJump(r.Xcng, n)
+	r.Xs = xsJump(r.Xs, n)
+	var rem uint64 // values left in Q before the next refill
+	if r.Index < QSIZE64 {
+		rem = QSIZE64 - r.Index
+	}
+	if n <= rem {
+		r.Index += n
+		return
+	}
+	n -= rem
+	refills := (n + QSIZE64 - 1) / QSIZE64
+	for i := refills; i > 0; i-- {
+		r.refill()
+	}
+	r.Index = n - (refills-1)*QSIZE64
+}
+
+// Discard advances SuperKISS64 generator r as if Uint64 had been called n
+// times with the results discarded.  It is Skip with an int argument, and
+// it panics if n < 0.
+func (r *SK64) Discard(n int) {
+	if n < 0 {
+		panic("SuperKISS64:Discard called with n < 0")
+	}
+	r.Skip(uint64(n))
+}
+
+// cngJump returns the state of the congruential generator cng after n
+// steps from state x, in O(log n) time.  See F. Brown, "Random Number
+// Generation with Arbitrary Strides", Trans. Am. Nucl. Soc. 71, 1994.
+func cngJump(x, n uint64) uint64 {
+	accMul, accAdd := uint64(1), uint64(0)
+	curMul, curAdd := uint64(6906969069), uint64(123)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			accMul *= curMul
+			accAdd = accAdd*curMul + curAdd
+		}
+		curAdd *= curMul + 1
+		curMul *= curMul
+	}
+	return accMul*x + accAdd
+}
+
+// xsPowers[k] is the 64x64 matrix over GF(2) of 2^k steps of xs, stored
+// as the images of the 64 unit vectors: column j is xs^(2^k)(1<<j).
+var (
+	xsPowers     [64][64]uint64
+	xsPowersOnce sync.Once
+)
+
+// gf2Apply returns the product of GF(2) matrix m, stored as in xsPowers,
+// and the bit vector x.
+func gf2Apply(m *[64]uint64, x uint64) (y uint64) {
+	for ; x != 0; x &= x - 1 {
+		y ^= m[bits.TrailingZeros64(x)]
+	}
+	return
+}
+
+// xsJump returns the state of the Xor-Shift generator xs after n steps
+// from state x, in O(log n) time.  xs is linear over GF(2), so n steps
+// are the product of the matrices in xsPowers for the set bits of n.
+func xsJump(x, n uint64) uint64 {
+	xsPowersOnce.Do(func() {
+		for j := range xsPowers[0] {
+			xsPowers[0][j] = xs(1 << j)
+		}
+		for k := 1; k < len(xsPowers); k++ {
+			for j := range xsPowers[k] {
+				xsPowers[k][j] = gf2Apply(&xsPowers[k-1],
+					xsPowers[k-1][j])
+			}
+		}
+	})
+	for ; n != 0; n &= n - 1 {
+		x = gf2Apply(&xsPowers[bits.TrailingZeros64(n)], x)
+	}
+	return x
+}
+
+// LockedSK64 is a SuperKISS64 generator guarded by a mutex, so a single
+// instance is safe for concurrent use by multiple goroutines.  It
+// implements the math/rand.Source64 and io.Reader interfaces.
+// New instances can be allocated using NewLockedSK64.
+type LockedSK64 struct {
+	mu  sync.Mutex
+	src *SK64
+}
+
+// NewLockedSK64 allocates a concurrency-safe SuperKISS64 PRNG initialized
+// with seed as by NewSuperKISS64.  NewLockedSK64 can be wrapped with
+// math/rand.New.
+func NewLockedSK64(seed int64) *LockedSK64 {
+	return &LockedSK64{src: NewSuperKISS64(seed)}
+}
+
+// Seed reinitializes r with seed as SK64.Seed does.
+func (r *LockedSK64) Seed(seed int64) {
+	r.mu.Lock()
+	r.src.Seed(seed)
+	r.mu.Unlock()
+}
+
+// Uint64 returns a 64-bit, uniformly distributed pseudorandom number
+// in the range [0,2^64).  This method implements the math/rand.Source64
+// interface.
+func (r *LockedSK64) Uint64() (n uint64) {
+	r.mu.Lock()
+	n = r.src.Uint64()
+	r.mu.Unlock()
+	return
+}
+
+// Int63 returns a uniformly distributed pseudorandom number in the range
+// [0,2^63).  This method implements the math/rand.Source interface.
+func (r *LockedSK64) Int63() (n int64) {
+	r.mu.Lock()
+	n = r.src.Int63()
+	r.mu.Unlock()
+	return
+}
+
+// Float64 returns a uniformly-distributed, pseudorandom float64 value in
+// range [0.0,1.0).
+func (r *LockedSK64) Float64() (x float64) {
+	r.mu.Lock()
+	x = r.src.Float64()
+	r.mu.Unlock()
+	return
+}
+
+// Read fills p with pseudorandom bytes.  This method implements the
+// io.Reader interface.  The returned length n is always len(p) and err is
+// always nil.
+func (r *LockedSK64) Read(p []byte) (n int, err error) {
+	r.mu.Lock()
+	n, err = r.src.Read(p)
+	r.mu.Unlock()
+	return
+}
+
+// Intn returns a uniformly distributed pseudorandom number in the range
+// [0,n).  It panics if n <= 0.
+func (r *LockedSK64) Intn(n int) (x int) {
+	r.mu.Lock()
+	defer r.mu.Unlock() // unlock even if src.Intn panics
+	return r.src.Intn(n)
+}
+
+var (
+	defaultOnce sync.Once
+	defaultSK64 *LockedSK64
+)
+
+// Default returns the package's shared LockedSK64 generator, which is
+// seeded from crypto/rand by NewSuperKISS64Rand on first use.  It backs the
+// package-level Uint64, Float64 and Intn functions, like the top-level
+// functions of math/rand, and is safe for concurrent use.
+func Default() *LockedSK64 {
+	defaultOnce.Do(func() {
+		defaultSK64 = &LockedSK64{src: NewSuperKISS64Rand()}
+	})
+	return defaultSK64
+}
+
+// Uint64 returns a 64-bit, uniformly distributed pseudorandom number from
+// the Default generator.
+func Uint64() uint64 {
+	return Default().Uint64()
+}
+
+// Float64 returns a uniformly-distributed, pseudorandom float64 value in
+// range [0.0,1.0) from the Default generator.
+func Float64() float64 {
+	return Default().Float64()
+}
+
+// Intn returns a uniformly distributed pseudorandom number in the range
+// [0,n) from the Default generator.  It panics if n <= 0.
+func Intn(n int) int {
+	return Default().Intn(n)
+}
+
+// XorSource combines two independently seeded SuperKISS64 generators by
+// XORing their outputs, as a belt-and-suspenders option for very large
+// Monte Carlo runs.  It implements the math/rand.Source64,
+// math/rand/v2.Source and io.Reader interfaces.  A single instance is not
+// safe for concurrent use.
+type XorSource struct {
+	a, b *SK64
+}
+
+// NewXorSource returns an XorSource combining generators a and b, which
+// should be seeded differently; XORing a stream with itself yields zeros.
+// The XorSource advances a and b as it is used.
+func NewXorSource(a, b *SK64) *XorSource {
+	return &XorSource{a: a, b: b}
+}
+
+// Seed seeds the first generator with Seed(seed) and the second with
+// SeedMix(seed, 1).  The different seeding methods start the two from
+// unrelated states, so the XOR is not of one stream with itself.  This
+// method is part of the math/rand.Source interface.
+func (x *XorSource) Seed(seed int64) {
+	x.a.Seed(seed)
+	x.b.SeedMix(seed, 1)
+}
+
+// Uint64 returns the XOR of the next Uint64 values of the two generators.
+// This method implements the math/rand.Source64 and math/rand/v2.Source
+// interfaces.
+func (x *XorSource) Uint64() uint64 {
+	return x.a.Uint64() ^ x.b.Uint64()
+}
+
+// Int63 returns a uniformly distributed pseudorandom number in the range
+// [0,2^63).  This method is part of the math/rand.Source interface.
+func (x *XorSource) Int63() int64 {
+	return int64(x.Uint64() >> 1)
+}
+
+// Read fills p with bytes packed from Uint64 values in the same way as
+// SK64.Read.  The returned length n is always len(p) and err is always
+// nil.  This method implements the io.Reader interface.
+func (x *XorSource) Read(p []byte) (n int, err error) {
+	return readWords(p, x.Uint64), nil
+}
+
+// Pool is a pool of SuperKISS64 generators, each seeded from crypto/rand
+// when first needed, for servers that want a generator per request
+// without contending for one LockedSK64 or reseeding each time.  Each
+// goroutine should Get a generator, use it, and Put it back when done;
+// a generator must not be used after it is Put.  A Pool is safe for
+// concurrent use, its zero value is ready to use, and it must not be
+// copied after first use.  Because Pool wraps sync.Pool, idle generators
+// may be dropped at any time.
+type Pool struct {
+	p sync.Pool
+}
+
+// Get returns a generator from the pool, allocating one with
+// NewSuperKISS64Rand if the pool is empty.
+func (p *Pool) Get() *SK64 {
+	if r, ok := p.p.Get().(*SK64); ok {
+		return r
+	}
+	return NewSuperKISS64Rand()
+}
+
+// Put returns generator r to the pool for reuse.  Put ignores a nil r.
+func (p *Pool) Put(r *SK64) {
+	if r != nil {
+		p.p.Put(r)
+	}
+}
@@ -3,184 +3,33 @@
 package SuperKISS64
 
 import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/fnv"
 	"io"
+	"io/fs"
 	"math"
 	"math/rand"
+	randv2 "math/rand/v2"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 )
 
 const alpha = 0.00001 // acceptable p-value limit
 
-// Ported by Ron Charlton from p_value.c on 2018-08-29.
-// From https://www.codeproject.com/Articles/432194/How-to-Calculate-the-Chi-Squared-P-Value
-// on 2018-08-29, where it was named chisqr.c. I (Ron) included gamma.c's
-// relevant function within this file for simplicity.  My port to the Go
-// programming language is in the public domain, as is the original.
-
-// Comment from chisqr.c:
-/*  Implementation of the Chi-Square Distribution, Gamma Function &
-	Incomplete Gamma Function in C
-
-	Written By Jacob Wells
-	July 31, 2012
-    Based on the formulas found here:
-
-    Wikipedia - Incomplete Gamma Function -> Evaluation formulae -> Connection
-	with Kummer's confluent hypergeometric function
-    http://en.wikipedia.org/wiki/Regularized_Gamma_function#Connection_with_Kummer.27s_confluent_hypergeometric_function
-
-    Wikipedia - Chi-squared Distribution -> Cumulative distribution function
-    http://en.wikipedia.org/wiki/Chi-squared_distribution#Cumulative_distribution_function
-
-    These functions are placed in the Public Domain, and may be used by anyone,
-	anywhere, for any reason, absolutely free of charge.
-*/
-
-// $Id: SuperKISS64_test.go,v 1.40 2024-07-08 07:01:37-04 ron Exp $
-
-/*
-c:\Users\Ron\go\src>go run TestPValue.go 255 160
-0.999999393099
-
-c:\Users\Ron\go\src>go run TestPValue.go 255 250
-0.57663526365
-
-c:\Users\Ron\go\src>go run TestPValue.go 255 300
-0.02772752205332
-*/
-
-// Dof is degrees of freedom.  Cv is critical value (chi-square).
-// Cv is sum( (observed - expected)^2 / expected )
-
-// PValue returns a p-value when given a degrees-of-freedom value and a
-// chi-square Critical value.
-func PValue(Dof int, Cv float64) float64 {
-	if Cv < 0 || Dof < 1 {
-		return 0.0
-	}
-
-	K := float64(Dof) * 0.5
-	X := Cv * 0.5
-
-	if Dof == 2 {
-		return math.Exp(-X)
-	}
-
-	PValue := 1.0 - math.Exp(LogIgamma(K, X)-LogGamma(K))
-
-	return PValue
-}
-
-/*
-	Returns the Natural Logarithm of the Incomplete Gamma Function.
-
-	I converted the p-value to work with Logarithms, and only calculate
-	the finished Value right at the end.  This allows us much more accurate
-	calculations.  One result of this is that I had to increase the Number
-	of Iterations from 200 to 1000.  Feel free to play around with this if
-	you like, but this is the only way I've gotten it to work.
-	Also, to make the code easier to work, I separated out the main loop.
-*/
-
-// LogIgamma returns the natural logarithm of the lower Incomplete Gamma
-// Function.
-func LogIgamma(S, Z float64) float64 {
-
-	if Z < 0.0 {
-		return 0.0
-	}
-
-	Sc := (math.Log(Z) * S) - Z - math.Log(S)
-
-	K := km(S, Z)
-
-	return math.Log(K) + Sc
-}
-
-func km(S, Z float64) float64 {
-	Sum := 1.0
-	Num := 1.0
-	Denom := 1.0
-
-	for I := 0; I < 1000; I++ {
-		Num *= Z
-		S++
-		Denom *= S
-		// The if statement was added by Ron Charlton to prevent invalidating
-		// Sum when using float64 numbers.
-		if Denom > 1.0e307 || Num > 1.0e307 {
-			break
-		}
-		Sum += (Num / Denom)
-	}
-
-	return Sum
-}
-
-// from gamma.c
-/*
-    Implementation of the Gamma function using Spouge's Approximation in C.
-
-    Written By Jacob F. Wells
-	7/31/2012
-    Public Domain
-
-    This code may be used by anyone for any reason
-    with no restrictions absolutely free of cost.
-*/
-
-const a float64 = 11 // 15 for long double
-/*
-    'a' is the level of accuracy you wish to calculate.
-    Spouge's Approximation is slightly tricky, as you
-    can only reach the desired level of precision if
-    you have EXTRA precision available so that it can
-    build up to the desired level.
-
-    If you're using double (64 bit wide datatype), you
-    will need to set a to 11, as well as remember to
-    change the math functions to the regular
-    (i.e. pow() instead of powl())
-
-   !! IF YOU GO OVER OR UNDER THESE VALUES YOU WILL !!!
-              !!! LOSE PRECISION !!!
-*/
-
-// LogGamma returns the natural logarithm of Gamma function using Spouge's
-// Approximation. The Gamma Function allows you to compute the Factorial
-// of decimals (e.g. 5.5!).
-func LogGamma(N float64) float64 {
-	// The constant SQRT2PI is defined as sqrt(2.0 * PI);
-	// For speed the constant is already defined in decimal
-	// form.  However, if you wish to ensure that you achieve
-	// maximum precision on your own machine, you can calculate
-	// it yourself using (sqrt(atan(1.0) * 8.0))
-
-	//var SQRT2PI float64 = math.Sqrt(math.Atan(1.0) * 8.0)
-	const SQRT2PI float64 = 2.5066282746310005024157652848110452530069867406099383
-
-	Z := N
-
-	Sc := (math.Log(Z+a) * (Z + 0.5)) - (Z + a) - math.Log(Z)
-
-	F := 1.0
-	Sum := SQRT2PI
-
-	for K := float64(1); K < a; K++ {
-		Z++
-		Ck := math.Pow(a-K, K-0.5)
-		Ck *= math.Exp(a - K)
-		Ck /= F
-
-		Sum += Ck / Z
-
-		F *= -K
-	}
-
-	return math.Log(Sum) + Sc
-}
-
 func TestCryptoSource(t *testing.T) {
 	pValueTest(NewSuperKISS64Rand(), t)
 
@@ -341,112 +190,2940 @@ func TestSK64SaveLoadWrapped(t *testing.T) {
 	os.Remove(fName)
 }
 
-// Calculate many p-values for many PRNG runs, then calculate the
-// p-value of the p-values, testing all p-values for acceptability.
-func pValueTest(rng *SK64, t *testing.T) {
-	binsOfPValues := make([]int, 10)       // 10 bins for p-values
-	PValueCount := len(binsOfPValues) * 10 // 10 average per bin (min. 5 req'd)
-	const binCount = 256                   // a byte can store 256 different values
-	buf := make([]byte, 800000)
-	for m := 0; m < PValueCount; m++ {
-		bins := make([]int, binCount)
-		rng.Read(buf)
-		for _, b := range buf {
-			bins[int(b)]++
-		}
-		expected := float64(len(buf)) / binCount
-		chiSquare := 0.0
-		for _, observed := range bins {
-			x := float64(observed) - expected
-			chiSquare += x * x / expected
-		}
-		dof := binCount - 1
-		pValue := PValue(dof, chiSquare)
-		if pValue == 1.0 {
-			pValue -= alpha / 2 // prevent index out-of-range
-		}
-		binsOfPValues[int(pValue*float64(len(binsOfPValues)))]++
-		if pValue < alpha || pValue > 1-alpha {
-			t.Errorf("Extreme p-value: %.15g.  This test is not "+
-				"deterministic.\n", pValue)
-		}
+func TestSK64Clone(t *testing.T) {
+	const n = QSIZE64 + 100 // cross a refill in both generators
+	r := NewSuperKISS64(42)
+	for i := 0; i < 1000; i++ {
+		r.Uint64()
 	}
-	// calculate p-value of p-values
-	expected := float64(PValueCount) / float64(len(binsOfPValues))
-	chiSquare := 0.0
-	for _, observed := range binsOfPValues {
-		x := float64(observed) - expected
-		chiSquare += x * x / expected
+
+	c := r.Clone()
+	if &c.Q[0] == &r.Q[0] {
+		t.Fatalf("Clone shares Q with the original")
 	}
-	dof := len(binsOfPValues) - 1
-	pValue := PValue(dof, chiSquare)
-	if pValue < alpha || pValue > 1-alpha {
-		t.Errorf("Extreme p-value of p-values: %.15g.  This test is not "+
-			"deterministic.\n", pValue)
+	want := make([]uint64, n)
+	for i := range want {
+		want[i] = r.Uint64()
+	}
+	for i, w := range want {
+		if got := c.Uint64(); got != w {
+			t.Fatalf("want %v but got %v at index %v", w, got, i)
+		}
+	}
+	var nilR *SK64
+	if nilR.Clone() != nil {
+		t.Errorf("Clone of nil did not return nil")
 	}
 }
 
-// Compile time test: CryptoSource implements the rand.Source interface.
-var _ rand.Source = &CryptoSource{}
+func TestSK64Equal(t *testing.T) {
+	var nilR *SK64
+	r := NewSuperKISS64(42)
+	c := r.Clone()
 
-// Compile time test: CryptoSource implements the rand.Source64 interface.
-var _ rand.Source64 = &CryptoSource{}
+	if !nilR.Equal(nil) {
+		t.Errorf("two nil generators are not equal")
+	}
+	if nilR.Equal(r) || r.Equal(nil) {
+		t.Errorf("nil and non-nil generators are equal")
+	}
+	if !r.Equal(c) || !c.Equal(r) {
+		t.Errorf("generator is not equal to its clone")
+	}
+	c.Q[QSIZE64-1]++
+	if r.Equal(c) {
+		t.Errorf("generators with different Q are equal")
+	}
+	c = r.Clone()
+	c.Uint64()
+	if r.Equal(c) {
+		t.Errorf("generators in different states are equal")
+	}
+	r.Uint64()
+	if !r.Equal(c) {
+		t.Errorf("generators in the same state are not equal")
+	}
+}
 
-// Compile time test: CryptoSource implements the io.Reader interface.
-var _ io.Reader = &CryptoSource{}
+func TestSK64Reset(t *testing.T) {
+	var z SK64
+	r := NewSuperKISS64(7)
+	q := &r.Q[0]
+	for seed := int64(0); seed < 5; seed++ {
+		r.Uint64()
+		r.Reset(seed)
+		z.Reset(seed)
+		if &r.Q[0] != q {
+			t.Errorf("Reset reallocated Q")
+		}
+		if want := NewSuperKISS64(seed); !r.Equal(want) || !z.Equal(want) {
+			t.Errorf("Reset(%d) differs from NewSuperKISS64(%d)", seed, seed)
+		}
+	}
+}
 
-// SuperKISS64 implementation tests:
-// Compile time test: SK64 implements the rand.Source interface.
-var _ rand.Source = &SK64{}
+func TestNormFloat64(t *testing.T) {
+	const n = 10000000
+	var sum, sumSq float64
+	r := NewSuperKISS64(12345)
+	for i := 0; i < n; i++ {
+		x := r.NormFloat64()
+		sum += x
+		sumSq += x * x
+	}
+	mean := sum / n
+	variance := sumSq/n - mean*mean
+	// The standard errors of mean and variance are about 0.0003 and 0.0004.
+	if math.Abs(mean) > 0.002 {
+		t.Errorf("NormFloat64 mean is %v; want about 0", mean)
+	}
+	if math.Abs(variance-1) > 0.003 {
+		t.Errorf("NormFloat64 variance is %v; want about 1", variance)
+	}
+}
 
-// Compile time test: SK64 implements the rand.Source64 interface.
-var _ rand.Source64 = &SK64{}
+func TestExpFloat64(t *testing.T) {
+	const n = 10000000
+	var sum float64
+	r := NewSuperKISS64(12345)
+	for i := 0; i < n; i++ {
+		x := r.ExpFloat64()
+		if x < 0 {
+			t.Fatalf("ExpFloat64 returned negative value %v", x)
+		}
+		sum += x
+	}
+	// The standard error of the mean is about 0.0003.
+	if mean := sum / n; math.Abs(mean-1) > 0.002 {
+		t.Errorf("ExpFloat64 mean is %v; want about 1", mean)
+	}
+}
 
-// Compile time test: SK64 implements the io.Reader interface.
-var _ io.Reader = &SK64{}
+func TestInt63nIntn(t *testing.T) {
+	const draws = 1000000
+	r := NewSuperKISS64(12345)
+	for _, n := range []int{7, 10, 16} {
+		bins63 := make([]int, n)
+		bins := make([]int, n)
+		for i := 0; i < draws; i++ {
+			bins63[r.Int63n(int64(n))]++
+			bins[r.Intn(n)]++
+		}
+		for _, b := range [][]int{bins63, bins} {
+			if p := binsPValue(b); p < alpha || p > 1-alpha {
+				t.Errorf("n = %d: extreme p-value %.15g for bins %v", n, p, b)
+			}
+		}
+	}
+	for _, f := range []func(){
+		func() { r.Int63n(0) },
+		func() { r.Intn(-1) },
+	} {
+		if !panics(f) {
+			t.Errorf("Int63n or Intn did not panic with n <= 0")
+		}
+	}
+}
 
-//////////////////////////////////////////////////////////////////////////////
-//==========================================================================//
-//\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\
+func TestUint64n(t *testing.T) {
+	const draws = 3000000
+	r := NewSuperKISS64(12345)
+	// 3 does not divide 2^64, so a modulo-biased method would show up here.
+	bins := make([]int, 3)
+	for i := 0; i < draws; i++ {
+		bins[r.Uint64n(3)]++
+	}
+	if p := binsPValue(bins); p < alpha || p > 1-alpha {
+		t.Errorf("extreme p-value %.15g for bins %v", p, bins)
+	}
+	// With n = 2^63 + 1 about half of all draws are rejected, and values
+	// below 2^62 must occur about half of the time.
+	const n = 1<<63 + 1
+	low := 0
+	for i := 0; i < draws; i++ {
+		v := r.Uint64n(n)
+		if v >= n {
+			t.Fatalf("Uint64n(%d) returned %d", uint64(n), v)
+		}
+		if v < 1<<62 {
+			low++
+		}
+	}
+	if p := binsPValue([]int{low, draws - low}); p < alpha || p > 1-alpha {
+		t.Errorf("Uint64n(2^63+1) is not uniform: %d of %d below 2^62", low,
+			draws)
+	}
+	if !panics(func() { r.Uint64n(0) }) {
+		t.Errorf("Uint64n did not panic with n == 0")
+	}
+}
 
-var v uint64
-var x float32
-var w []byte
+func TestShuffle(t *testing.T) {
+	deck := func(seed int64) []int {
+		d := make([]int, 52)
+		for i := range d {
+			d[i] = i
+		}
+		NewSuperKISS64(seed).Shuffle(len(d), func(i, j int) {
+			d[i], d[j] = d[j], d[i]
+		})
+		return d
+	}
+	d := deck(7)
+	if !isPerm(d) {
+		t.Errorf("Shuffle result is not a permutation: %v", d)
+	}
+	if !slices.Equal(d, deck(7)) {
+		t.Errorf("Shuffle is not reproducible from a fixed seed")
+	}
+	if slices.Equal(d, deck(8)) {
+		t.Errorf("Shuffle gave identical results for different seeds")
+	}
+	if !panics(func() { New().Shuffle(-1, func(i, j int) {}) }) {
+		t.Errorf("Shuffle did not panic with n < 0")
+	}
+}
 
-func BenchmarkCryptoSource(b *testing.B) {
-	b.SetBytes(8)
-	r := NewCryptoSource()
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		v = r.Uint64()
+func TestPerm(t *testing.T) {
+	r := NewSuperKISS64(7)
+	for _, n := range []int{0, 1, 2, 10, 52, 1000} {
+		p := r.Perm(n)
+		if p == nil || len(p) != n || !isPerm(p) {
+			t.Errorf("Perm(%d) returned %v", n, p)
+		}
+	}
+	if !panics(func() { r.Perm(-1) }) {
+		t.Errorf("Perm did not panic with n < 0")
 	}
 }
 
-func BenchmarkSuperKISS64(b *testing.B) {
-	b.SetBytes(8)
+func TestSK64JSON(t *testing.T) {
 	r := NewSuperKISS64Rand()
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		v = r.Uint64()
+	r.Uint64()
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	var z SK64
+	if err = json.Unmarshal(data, &z); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if !z.Equal(r) {
+		t.Fatalf("unmarshaled state differs from marshaled state")
+	}
+	for i := 0; i < QSIZE64+100; i++ {
+		if want, got := r.Uint64(), z.Uint64(); got != want {
+			t.Fatalf("want %v but got %v at index %v", want, got, i)
+		}
+	}
+
+	short := `{"Carry":1,"Xcng":2,"Xs":3,"Index":4,"Q":[5,6],"Seeded":true}`
+	c := z.Clone()
+	if err = json.Unmarshal([]byte(short), &z); err == nil {
+		t.Errorf("Unmarshal accepted a short Q")
+	}
+	if !z.Equal(c) {
+		t.Errorf("failed Unmarshal changed the generator")
 	}
 }
 
-func BenchmarkFloat32(b *testing.B) {
-	b.SetBytes(4)
+func TestSK64Gob(t *testing.T) {
+	var buf bytes.Buffer
 	r := NewSuperKISS64Rand()
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		x = r.Float32()
+	r.Uint64()
+	if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	var z SK64
+	if err := gob.NewDecoder(&buf).Decode(&z); err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	if !z.Equal(r) {
+		t.Fatalf("decoded state differs from encoded state")
+	}
+	for i := 0; i < QSIZE64+100; i++ {
+		if want, got := r.Uint64(), z.Uint64(); got != want {
+			t.Fatalf("want %v but got %v at index %v", want, got, i)
+		}
+	}
+
+	b, _ := r.GobEncode()
+	c := z.Clone()
+	if err := z.GobDecode(b[:len(b)-8]); err == nil {
+		t.Errorf("GobDecode accepted a short Q")
+	}
+	if !z.Equal(c) {
+		t.Errorf("failed GobDecode changed the generator")
 	}
 }
 
-func BenchmarkRead(b *testing.B) {
-	const size = 1e6
-	w = make([]byte, size)
-	b.SetBytes(size)
+func TestSK64Binary(t *testing.T) {
 	r := NewSuperKISS64Rand()
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		r.Read(w)
+	r.Uint64()
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary returned error: %v", err)
+	}
+	var z SK64
+	if err = z.UnmarshalBinary(b); err != nil {
+		t.Fatalf("UnmarshalBinary returned error: %v", err)
+	}
+	for i := 0; i < QSIZE64+100; i++ {
+		if want, got := r.Uint64(), z.Uint64(); got != want {
+			t.Fatalf("want %v but got %v at index %v", want, got, i)
+		}
+	}
+
+	bad := append([]byte(nil), b...)
+	bad[0] = 'X'
+	c := z.Clone()
+	for _, data := range [][]byte{nil, b[:3], b[:5], b[:len(b)-1], bad} {
+		if err = z.UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary accepted bad data of length %d",
+				len(data))
+		}
+	}
+	if !z.Equal(c) {
+		t.Errorf("failed UnmarshalBinary changed the generator")
+	}
+}
+
+func TestSK64WriteReadState(t *testing.T) {
+	for _, gz := range []bool{false, true} {
+		var buf bytes.Buffer
+		var err error
+		r := NewSuperKISS64Rand()
+		r.Uint64()
+		if gz {
+			err = r.WriteStateGzip(&buf)
+		} else {
+			err = r.WriteState(&buf)
+		}
+		if err != nil {
+			t.Fatalf("WriteState (gzip %v) returned error: %v", gz, err)
+		}
+		var z SK64
+		if gz {
+			err = z.ReadStateGzip(&buf)
+		} else {
+			err = z.ReadState(&buf)
+		}
+		if err != nil {
+			t.Fatalf("ReadState (gzip %v) returned error: %v", gz, err)
+		}
+		if !z.Equal(r) {
+			t.Errorf("state read (gzip %v) differs from state written", gz)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	for _, m := range []uint64{0, 1, 5, 1000, QSIZE64 - 1, QSIZE64,
+		QSIZE64 + 1, 3*QSIZE64 + 17} {
+		r := NewSuperKISS64(99)
+		r.Skip(123) // start mid-Q
+		z := r.Clone()
+		r.Skip(m)
+		for i := uint64(0); i < m; i++ {
+			z.Uint64()
+		}
+		if !r.Equal(z) {
+			t.Errorf("Skip(%d) state differs from %d calls to Uint64", m, m)
+		}
+		if want, got := z.Uint64(), r.Uint64(); got != want {
+			t.Errorf("after Skip(%d) want %v but got %v", m, want, got)
+		}
+	}
+}
+
+func TestXsJump(t *testing.T) {
+	x := uint64(521288629546311)
+	for _, n := range []uint64{0, 1, 2, 3, 64, 1000, 3*QSIZE64 + 17} {
+		want := x
+		for i := uint64(0); i < n; i++ {
+			want = xs(want)
+		}
+		if got := xsJump(x, n); got != want {
+			t.Errorf("xsJump(%#x, %d) = %#x; want %#x", x, n, got, want)
+		}
+	}
+	// Jumps compose, including across the top bit of n.
+	a, b := uint64(1)<<63+12345, uint64(1)<<62+6789
+	if xsJump(xsJump(x, a), b) != xsJump(xsJump(x, b), a) {
+		t.Error("xsJump does not commute")
+	}
+	if xsJump(xsJump(x, 1<<40), 1<<40) != xsJump(x, 1<<41) {
+		t.Error("two jumps of 2^40 differ from one jump of 2^41")
+	}
+}
+
+func TestLockedSK64(t *testing.T) {
+	const goroutines, calls = 16, 2000
+	r := NewLockedSK64(1)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b := make([]byte, 13)
+			for i := 0; i < calls; i++ {
+				r.Uint64()
+				r.Int63()
+				if x := r.Float64(); x < 0 || x >= 1 {
+					t.Errorf("Float64 returned %v", x)
+				}
+				r.Read(b)
+			}
+			r.Seed(2)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDiscard(t *testing.T) {
+	r := NewSuperKISS64(5)
+	z := r.Clone()
+	r.Discard(5)
+	for i := 0; i < 5; i++ {
+		z.Uint64()
+	}
+	if !r.Equal(z) {
+		t.Errorf("Discard(5) state differs from 5 calls to Uint64")
+	}
+	if !panics(func() { r.Discard(-1) }) {
+		t.Errorf("Discard did not panic with n < 0")
+	}
+}
+
+func TestNewSubstreams(t *testing.T) {
+	const count, n = 8, 100
+	a := NewSubstreams(2024, count)
+	b := NewSubstreams(2024, count)
+	seen := make(map[uint64]bool)
+	for k := 0; k < count; k++ {
+		if !a[k].Equal(b[k]) {
+			t.Errorf("substream %d is not reproducible", k)
+		}
+		for i := 0; i < n; i++ {
+			v := a[k].Uint64()
+			if seen[v] {
+				t.Fatalf("substream %d repeated value %v", k, v)
+			}
+			seen[v] = true
+		}
+	}
+	if len(NewSubstreams(1, 0)) != 0 {
+		t.Errorf("NewSubstreams(1, 0) returned generators")
+	}
+}
+
+func TestFillUint64(t *testing.T) {
+	r := NewSuperKISS64(3)
+	z := r.Clone()
+	sizes := []int{0, 1, 7, 1000, QSIZE64 - 1, QSIZE64, 2*QSIZE64 + 5}
+	for _, n := range sizes {
+		dst := make([]uint64, n)
+		r.FillUint64(dst)
+		for i, got := range dst {
+			if want := z.Uint64(); got != want {
+				t.Fatalf("n = %d: want %v but got %v at index %v", n, want,
+					got, i)
+			}
+		}
+		if !r.Equal(z) {
+			t.Errorf("n = %d: FillUint64 state differs from Uint64 calls", n)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	r := NewSuperKISS64(11)
+	z := NewSuperKISS64(11)
+	b1, b2 := r.Bytes(37), r.Bytes(37)
+	if len(b1) != 37 || len(b2) != 37 {
+		t.Fatalf("Bytes(37) returned lengths %d and %d", len(b1), len(b2))
+	}
+	if bytes.Equal(b1, b2) {
+		t.Errorf("two calls of Bytes returned identical bytes")
+	}
+	if !bytes.Equal(b1, z.Bytes(37)) {
+		t.Errorf("generators with the same seed returned different bytes")
+	}
+	if b := r.Bytes(0); b == nil || len(b) != 0 {
+		t.Errorf("Bytes(0) returned %v", b)
+	}
+	if !panics(func() { r.Bytes(-1) }) {
+		t.Errorf("Bytes did not panic with n < 0")
+	}
+}
+
+func TestIntRange(t *testing.T) {
+	const draws = 700000
+	r := NewSuperKISS64(17)
+	bins := make([]int, 7)
+	for i := 0; i < draws; i++ {
+		v := r.IntRange(-10, -4)
+		if v < -10 || v > -4 {
+			t.Fatalf("IntRange(-10, -4) returned %d", v)
+		}
+		bins[v+10]++
+	}
+	if p := binsPValue(bins); p < alpha || p > 1-alpha {
+		t.Errorf("extreme p-value %.15g for bins %v", p, bins)
+	}
+	for _, v := range []int64{math.MinInt64, -1, 0, 42, math.MaxInt64} {
+		if got := r.Int64Range(v, v); got != v {
+			t.Errorf("Int64Range(%d, %d) returned %d", v, v, got)
+		}
+	}
+	neg := 0
+	for i := 0; i < 1000; i++ {
+		if r.Int64Range(math.MinInt64, math.MaxInt64) < 0 {
+			neg++
+		}
+	}
+	if neg < 400 || neg > 600 {
+		t.Errorf("full-range Int64Range was negative %d times in 1000", neg)
+	}
+	if v := r.Int64Range(math.MinInt64, math.MinInt64+1); v > math.MinInt64+1 {
+		t.Errorf("Int64Range(MinInt64, MinInt64+1) returned %d", v)
+	}
+	if !panics(func() { r.IntRange(1, 0) }) {
+		t.Errorf("IntRange did not panic with min > max")
+	}
+}
+
+func TestFloat64Range(t *testing.T) {
+	r := NewSuperKISS64(19)
+	for _, b := range [][2]float64{
+		{0, 1}, {-5, -2}, {-1e-300, 1e-300}, {1, math.Nextafter(1, 2)},
+		{-math.MaxFloat64, math.MaxFloat64},
+	} {
+		for i := 0; i < 100000; i++ {
+			if x := r.Float64Range(b[0], b[1]); x < b[0] || x >= b[1] {
+				t.Fatalf("Float64Range(%v, %v) returned %v", b[0], b[1], x)
+			}
+		}
+	}
+	if x := r.Float64Range(3, 3); x != 3 {
+		t.Errorf("Float64Range(3, 3) returned %v", x)
+	}
+	if !panics(func() { r.Float64Range(1, 0) }) {
+		t.Errorf("Float64Range did not panic with min > max")
+	}
+}
+
+func TestBool(t *testing.T) {
+	const draws = 1000000
+	r := NewSuperKISS64(23)
+	trues := 0
+	for i := 0; i < draws; i++ {
+		if r.Bool() {
+			trues++
+		}
+	}
+	if p := binsPValue([]int{trues, draws - trues}); p < alpha || p > 1-alpha {
+		t.Errorf("Bool returned true %d times in %d", trues, draws)
+	}
+
+	// The buffered bits must survive a save and load.
+	r.Bool()
+	b, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary returned error: %v", err)
+	}
+	var z SK64
+	if err = z.UnmarshalBinary(b); err != nil {
+		t.Fatalf("UnmarshalBinary returned error: %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		if want, got := r.Bool(), z.Bool(); got != want {
+			t.Fatalf("want %v but got %v at index %v", want, got, i)
+		}
+	}
+
+	// Version 1 data has no buffered bits or draw count.
+	v1 := append([]byte(nil), b[:len(binaryMagic)+1+4*8+1]...)
+	v1[len(binaryMagic)] = 1
+	v1 = append(v1, b[len(v1)+3*8:]...)
+	if err = z.UnmarshalBinary(v1); err != nil {
+		t.Fatalf("UnmarshalBinary of version 1 data returned error: %v", err)
+	}
+	if z.NBits != 0 || z.Carry != r.Carry || z.Q[QSIZE64-1] != r.Q[QSIZE64-1] {
+		t.Errorf("version 1 data was decoded incorrectly")
+	}
+}
+
+func TestUint32Int31(t *testing.T) {
+	const draws = 1000000
+	r := NewSuperKISS64(29)
+	z := r.Clone()
+	for i := 0; i < 100; i++ {
+		v := z.Uint64()
+		if lo, hi := r.Uint32(), r.Uint32(); lo != uint32(v) ||
+			hi != uint32(v>>32) {
+			t.Fatalf("Uint32 halves %x %x do not match Uint64 %x", lo, hi, v)
+		}
+	}
+	lows, highs := make([]int, 256), make([]int, 256)
+	for i := 0; i < draws; i++ {
+		for _, bins := range [][]int{lows, highs} {
+			v := r.Uint32()
+			for j := 0; j < 4; j++ {
+				bins[byte(v>>(8*j))]++
+			}
+		}
+	}
+	for _, bins := range [][]int{lows, highs} {
+		if p := binsPValue(bins); p < alpha || p > 1-alpha {
+			t.Errorf("extreme p-value %.15g for Uint32 halves", p)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		if v := r.Int31(); v < 0 {
+			t.Fatalf("Int31 returned %d", v)
+		}
+	}
+}
+
+func TestSeedFromString(t *testing.T) {
+	r := NewSuperKISS64FromString("experiment-2024-run3")
+	z := &SK64{Q: make([]uint64, QSIZE64)}
+	z.SeedFromString("experiment-2024-run3")
+	if !r.Equal(z) {
+		t.Errorf("identical strings gave different states")
+	}
+	z.SeedFromString("experiment-2024-run4")
+	same := 0
+	for i := 0; i < 100; i++ {
+		if r.Uint64() == z.Uint64() {
+			same++
+		}
+	}
+	if same > 0 {
+		t.Errorf("different strings gave %d identical values in 100", same)
+	}
+}
+
+func TestSeedFromBytes(t *testing.T) {
+	b := []byte("0123456789abcdefghij") // not a multiple of 8 bytes
+	r := &SK64{Q: make([]uint64, QSIZE64)}
+	z := &SK64{Q: make([]uint64, QSIZE64)}
+	r.SeedFromBytes(b)
+	z.SeedFromSlice([]uint64{
+		binary.LittleEndian.Uint64([]byte("01234567")),
+		binary.LittleEndian.Uint64([]byte("89abcdef")),
+		binary.LittleEndian.Uint64([]byte("ghij\x00\x00\x00\x00")),
+	})
+	if !r.Equal(z) {
+		t.Errorf("SeedFromBytes differs from SeedFromSlice of packed bytes")
+	}
+	b[len(b)-1]++
+	z.SeedFromBytes(b)
+	if r.Uint64() == z.Uint64() {
+		t.Errorf("changing one byte did not change the sequence")
+	}
+	r.SeedFromBytes(nil)
+	z.SeedFromSlice(nil)
+	if !r.Equal(z) {
+		t.Errorf("SeedFromBytes(nil) differs from SeedFromSlice(nil)")
+	}
+}
+
+func TestSeedFromReader(t *testing.T) {
+	full := NewSuperKISS64(31).Bytes(QSIZE64*8 + 100)
+	r := &SK64{Q: make([]uint64, QSIZE64)}
+	z := &SK64{Q: make([]uint64, QSIZE64)}
+	for _, n := range []int{len(full), QSIZE64 * 8, 1000, 8} {
+		if err := r.SeedFromReader(bytes.NewReader(full[:n])); err != nil {
+			t.Fatalf("SeedFromReader of %d bytes returned error: %v", n, err)
+		}
+		m := min(n, QSIZE64*8)
+		z.SeedFromBytes(full[:m])
+		if !r.Equal(z) {
+			t.Errorf("SeedFromReader of %d bytes differs from SeedFromBytes", n)
+		}
+	}
+	c := r.Clone()
+	for _, n := range []int{0, 7} {
+		if err := r.SeedFromReader(bytes.NewReader(full[:n])); err == nil {
+			t.Errorf("SeedFromReader of %d bytes did not return an error", n)
+		}
+	}
+	if !r.Equal(c) {
+		t.Errorf("failed SeedFromReader changed the generator")
+	}
+}
+
+func TestSK64SaveLoadStateBinary(t *testing.T) {
+	dir := t.TempDir()
+	r := NewSuperKISS64Rand()
+	r.Uint64()
+	for _, ext := range []string{"", ".gz"} {
+		bName := filepath.Join(dir, "state.bin"+ext)
+		xName := filepath.Join(dir, "state.xml"+ext)
+		if err := r.SaveStateBinary(bName); err != nil {
+			t.Fatalf("SaveStateBinary returned error: %v", err)
+		}
+		if err := r.SaveState(xName); err != nil {
+			t.Fatalf("SaveState returned error: %v", err)
+		}
+		var z SK64
+		if err := z.LoadStateBinary(bName); err != nil {
+			t.Fatalf("LoadStateBinary returned error: %v", err)
+		}
+		if !z.Equal(r) {
+			t.Errorf("state loaded from %q differs from state saved", bName)
+		}
+		bInfo, _ := os.Stat(bName)
+		xInfo, _ := os.Stat(xName)
+		if bInfo.Size() >= xInfo.Size() {
+			t.Errorf("binary file %q size %d is not smaller than XML size %d",
+				bName, bInfo.Size(), xInfo.Size())
+		}
+	}
+}
+
+func TestSK64LoadStateShortQ(t *testing.T) {
+	fName := filepath.Join(t.TempDir(), "short.xml")
+	short := &SK64{Q: []uint64{1, 2, 3}, Seeded: true}
+	if err := short.SaveState(fName); err != nil {
+		t.Fatalf("SaveState returned error: %v", err)
+	}
+	r := NewSuperKISS64(37)
+	c := r.Clone()
+	if err := r.LoadState(fName); err == nil {
+		t.Errorf("LoadState accepted a short Q")
+	}
+	if !r.Equal(c) {
+		t.Errorf("failed LoadState changed the generator")
+	}
+	if z, err := SK64LoadState(fName); err == nil || z != nil {
+		t.Errorf("SK64LoadState accepted a short Q")
+	}
+}
+
+func TestConcurrentSeeding(t *testing.T) {
+	// Run with -race: seeding distinct generators must not share state.
+	const goroutines = 8
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := NewSuperKISS64(seed)
+			r.SeedFromSlice([]uint64{uint64(seed)})
+			r.Reset(seed + 1)
+		}(int64(g + 1))
+	}
+	wg.Wait()
+}
+
+func TestWriteN(t *testing.T) {
+	const n = 100003
+	var buf bytes.Buffer
+	r := NewSuperKISS64(41)
+	written, err := r.WriteN(&buf, n)
+	if err != nil || written != n || buf.Len() != n {
+		t.Fatalf("WriteN wrote %d (buffer %d) with error %v; want %d",
+			written, buf.Len(), err, n)
+	}
+	if !bytes.Equal(buf.Bytes(), NewSuperKISS64(41).Bytes(n)) {
+		t.Errorf("WriteN bytes differ from Bytes of the same length")
+	}
+	if written, err = r.WriteN(&buf, -1); written != 0 || err != nil {
+		t.Errorf("WriteN(-1) wrote %d with error %v", written, err)
+	}
+}
+
+func TestReadByte(t *testing.T) {
+	r := NewSuperKISS64(43)
+	want := binary.LittleEndian.AppendUint64(nil, r.Clone().Uint64())
+	for i, w := range want {
+		if got, err := r.ReadByte(); got != w || err != nil {
+			t.Errorf("want %v but got %v, %v at index %v", w, got, err, i)
+		}
+	}
+	r.ReadByte()
+	z := r.Clone()
+	b, err := r.MarshalBinary()
+	if err == nil {
+		err = z.UnmarshalBinary(b)
+	}
+	if err != nil {
+		t.Fatalf("state round trip returned error: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		want, _ := r.ReadByte()
+		if got, _ := z.ReadByte(); got != want {
+			t.Fatalf("want %v but got %v at index %v after load", want, got, i)
+		}
+	}
+}
+
+func TestChoice(t *testing.T) {
+	const draws = 600000
+	r := NewSuperKISS64(47)
+	s := []string{"a", "b", "c", "d", "e", "f"}
+	counts := make(map[string]int)
+	for i := 0; i < draws; i++ {
+		counts[Choice(r, s)]++
+	}
+	bins := make([]int, len(s))
+	for i, v := range s {
+		bins[i] = counts[v]
+	}
+	if p := binsPValue(bins); p < alpha || p > 1-alpha {
+		t.Errorf("extreme p-value %.15g for counts %v", p, counts)
+	}
+	if !panics(func() { Choice(r, []int{}) }) {
+		t.Errorf("Choice did not panic with an empty slice")
+	}
+}
+
+func TestWeightedIndex(t *testing.T) {
+	const draws = 1000000
+	r := NewSuperKISS64(53)
+	weights := []float64{0, 1, 2, 0, 7, 0}
+	counts := make([]int, len(weights))
+	for i := 0; i < draws; i++ {
+		counts[r.WeightedIndex(weights)]++
+	}
+	for i, w := range weights {
+		got := float64(counts[i]) / draws
+		if want := w / 10; math.Abs(got-want) > 0.003 {
+			t.Errorf("index %d chosen with frequency %v; want %v", i, got,
+				want)
+		}
+	}
+	for _, w := range [][]float64{nil, {0, 0}, {1, -1}, {math.NaN()}} {
+		if !panics(func() { r.WeightedIndex(w) }) {
+			t.Errorf("WeightedIndex(%v) did not panic", w)
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	const n, k, runs = 10, 4, 100000
+	r := NewSuperKISS64(59)
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	counts := make([]int, n)
+	for run := 0; run < runs; run++ {
+		got := Sample(r, s, k)
+		seen := make(map[int]bool)
+		for _, v := range got {
+			if seen[v] {
+				t.Fatalf("Sample returned duplicate %d in %v", v, got)
+			}
+			seen[v] = true
+			counts[v]++
+		}
+	}
+	if p := binsPValue(counts); p < alpha || p > 1-alpha {
+		t.Errorf("extreme p-value %.15g for counts %v", p, counts)
+	}
+	if got := Sample(r, s, n); !isPerm(got) {
+		t.Errorf("Sample of every element is not a permutation: %v", got)
+	}
+	if !panics(func() { Sample(r, s, n+1) }) {
+		t.Errorf("Sample did not panic with k > len(s)")
+	}
+}
+
+func TestReservoirSample(t *testing.T) {
+	const items, n, runs = 20, 5, 100000
+	r := NewSuperKISS64(61)
+	counts := make([]int, items)
+	for run := 0; run < runs; run++ {
+		i := 0
+		next := func() (int, bool) {
+			i++
+			return i - 1, i <= items
+		}
+		for _, v := range ReservoirSample(r, n, next) {
+			counts[v]++
+		}
+	}
+	if p := binsPValue(counts); p < alpha || p > 1-alpha {
+		t.Errorf("extreme p-value %.15g for counts %v", p, counts)
+	}
+	i := 0
+	short := ReservoirSample(r, n, func() (int, bool) { i++; return i, i < 3 })
+	if len(short) != 2 {
+		t.Errorf("sample of a 2-item stream has %d items", len(short))
+	}
+}
+
+func TestNewCryptoSourceSize(t *testing.T) {
+	const draws = 200000
+	for _, words := range []int{-5, 0, 1} {
+		r := NewCryptoSourceSize(words)
+		if len(r.buf) != u64bytes {
+			t.Errorf("NewCryptoSourceSize(%d) buffer is %d bytes", words,
+				len(r.buf))
+		}
+		bins := make([]int, 256)
+		for i := 0; i < draws; i++ {
+			v := r.Uint64()
+			for j := 0; j < 8; j++ {
+				bins[byte(v>>(8*j))]++
+			}
+		}
+		if p := binsPValue(bins); p < alpha || p > 1-alpha {
+			t.Errorf("extreme p-value %.15g with %d words", p, words)
+		}
+	}
+}
+
+func TestCryptoSourceErrors(t *testing.T) {
+	errEntropy := errors.New("entropy source failed")
+	r := NewCryptoSource()
+	r.rd = iotest.ErrReader(errEntropy)
+	if _, err := r.TryUint64(); !errors.Is(err, errEntropy) {
+		t.Errorf("TryUint64 returned error %v; want %v", err, errEntropy)
+	}
+	if err := r.Fill(make([]byte, 10)); !errors.Is(err, errEntropy) {
+		t.Errorf("Fill returned error %v; want %v", err, errEntropy)
+	}
+	if !panics(func() { r.Uint64() }) {
+		t.Errorf("Uint64 did not panic when the entropy source failed")
+	}
+	r.rd = nil // back to crypto/rand
+	if _, err := r.TryUint64(); err != nil {
+		t.Errorf("TryUint64 returned error %v after recovery", err)
+	}
+}
+
+func TestNewCryptoSourceWithReader(t *testing.T) {
+	content := make([]byte, 64*8) // two buffers' worth
+	for i := range content {
+		content[i] = byte(i * 7)
+	}
+	r := NewCryptoSourceWithReader(bytes.NewReader(content))
+	for i := 0; i < len(content)/8; i++ {
+		want := binary.LittleEndian.Uint64(content[i*8:])
+		if got := r.Uint64(); got != want {
+			t.Fatalf("want %x but got %x at index %v", want, got, i)
+		}
+	}
+	if _, err := r.TryUint64(); err == nil {
+		t.Errorf("TryUint64 did not return an error at end of content")
+	}
+	p := make([]byte, 3)
+	r = NewCryptoSourceWithReader(bytes.NewReader(content))
+	n, err := r.Read(p)
+	if n != 3 || err != nil || !bytes.Equal(p, content[:3]) {
+		t.Errorf("Read returned %v, %d, %v", p, n, err)
+	}
+}
+
+// cancelReader reads from rd and calls cancel after its first Read.
+type cancelReader struct {
+	rd     io.Reader
+	cancel func()
+}
+
+func (c *cancelReader) Read(p []byte) (int, error) {
+	defer c.cancel()
+	return c.rd.Read(p)
+}
+
+func TestCryptoSourceRemaining(t *testing.T) {
+	r := NewCryptoSourceSize(4)
+	if n := r.Remaining(); n != 0 {
+		t.Errorf("new CryptoSource has %d bytes remaining; want 0", n)
+	}
+	r.Uint64() // refill
+	for want := 3 * u64bytes; want >= 0; want -= u64bytes {
+		if n := r.Remaining(); n != want {
+			t.Fatalf("Remaining = %d; want %d", n, want)
+		}
+		if want > 0 {
+			r.Uint64()
+		}
+	}
+	r.Uint64() // refill
+	if n := r.Remaining(); n != 3*u64bytes {
+		t.Errorf("Remaining after refill = %d; want %d", n, 3*u64bytes)
+	}
+	r.Reset()
+	if n := r.Remaining(); n != 0 {
+		t.Errorf("Remaining after Reset = %d; want 0", n)
+	}
+	r.Uint64()
+	if n := r.Remaining(); n != 3*u64bytes {
+		t.Errorf("Remaining after Reset and Uint64 = %d; want %d",
+			n, 3*u64bytes)
+	}
+}
+
+func TestCryptoSourceReadContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewCryptoSourceWithReader(&cancelReader{NewSuperKISS64(79), cancel})
+	p := make([]byte, 1<<20)
+	n, err := r.ReadContext(ctx, p)
+	if !errors.Is(err, context.Canceled) || n != readContextChunk {
+		t.Errorf("ReadContext after cancel returned %d, %v; want %d, %v",
+			n, err, readContextChunk, context.Canceled)
+	}
+	if n, err := r.ReadContext(ctx, p); n != 0 || err != context.Canceled {
+		t.Errorf("ReadContext with done context returned %d, %v", n, err)
+	}
+	n, err = NewCryptoSource().ReadContext(context.Background(), p[:10000])
+	if n != 10000 || err != nil {
+		t.Errorf("ReadContext returned %d, %v; want 10000, nil", n, err)
+	}
+}
+
+func TestSK64String(t *testing.T) {
+	r := NewSuperKISS64(67)
+	s := r.String()
+	for _, want := range []string{"Seeded:true", "Index:20632",
+		fmt.Sprintf("Xcng:%#x", r.Xcng), "Q[len=20632]"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("String() = %q does not contain %q", s, want)
+		}
+	}
+	if len(s) > 200 {
+		t.Errorf("String() is %d bytes long", len(s))
+	}
+	var nilR *SK64
+	if s = nilR.String(); s != "SK64(nil)" {
+		t.Errorf("String() of nil = %q", s)
+	}
+}
+
+func ExampleChiSquareUniformity() {
+	r := NewSuperKISS64(2024)
+	p := ChiSquareUniformity(r, 1000000)
+	fmt.Println(p > 0.00001 && p < 0.99999)
+	// Output: true
+}
+
+func TestKSTestUniform(t *testing.T) {
+	r := NewSuperKISS64(77)
+	d, p := KSTestUniform(r, 100000)
+	if p < 0.00001 || p > 0.99999 {
+		t.Errorf("KSTestUniform p-value %g (D=%g) is implausible", p, d)
+	}
+	// A large D for many samples must give a tiny p-value.
+	if p := ksProb(math.Sqrt(1000) * 0.5); p > 1e-6 {
+		t.Errorf("ksProb for D=0.5, n=1000 gave %g; want ~0", p)
+	}
+	if p := ksProb(0); p != 1 {
+		t.Errorf("ksProb(0) = %g; want 1", p)
+	}
+	if !panics(func() { KSTestUniform(r, 0) }) {
+		t.Error("KSTestUniform(r, 0) did not panic")
+	}
+}
+
+func TestSerialCorrelation(t *testing.T) {
+	const n = 1000000
+	r := NewSuperKISS64(99)
+	// The coefficient's standard deviation is about 1/sqrt(n) = 0.001.
+	if c := SerialCorrelation(r, n); math.Abs(c) > 0.005 {
+		t.Errorf("SerialCorrelation = %g; want near 0", c)
+	}
+	if !panics(func() { SerialCorrelation(r, 1) }) {
+		t.Error("SerialCorrelation(r, 1) did not panic")
+	}
+}
+
+// meanVariance returns the sample mean and variance of n values from f.
+func meanVariance(n int, f func() float64) (mean, variance float64) {
+	var sum, sumSq float64
+	for i := 0; i < n; i++ {
+		x := f()
+		sum += x
+		sumSq += x * x
+	}
+	mean = sum / float64(n)
+	variance = sumSq/float64(n) - mean*mean
+	return
+}
+
+func TestGamma(t *testing.T) {
+	const n = 1000000
+	r := NewSuperKISS64(12345)
+	for _, c := range []struct{ shape, scale float64 }{
+		{0.3, 1}, {1, 2}, {2.5, 0.5}, {30, 3},
+	} {
+		mean, variance := meanVariance(n, func() float64 {
+			x := r.Gamma(c.shape, c.scale)
+			if x < 0 {
+				t.Fatalf("Gamma(%v, %v) returned negative %v",
+					c.shape, c.scale, x)
+			}
+			return x
+		})
+		wantMean := c.shape * c.scale
+		wantVar := wantMean * c.scale
+		if math.Abs(mean-wantMean) > 0.01*wantMean {
+			t.Errorf("Gamma(%v, %v) mean is %v; want about %v",
+				c.shape, c.scale, mean, wantMean)
+		}
+		if math.Abs(variance-wantVar) > 0.03*wantVar {
+			t.Errorf("Gamma(%v, %v) variance is %v; want about %v",
+				c.shape, c.scale, variance, wantVar)
+		}
+	}
+	for _, bad := range [][2]float64{{0, 1}, {1, 0}, {-1, 1}, {math.NaN(), 1}} {
+		if !panics(func() { r.Gamma(bad[0], bad[1]) }) {
+			t.Errorf("Gamma(%v, %v) did not panic", bad[0], bad[1])
+		}
+	}
+}
+
+func TestPoisson(t *testing.T) {
+	const n = 1000000
+	r := NewSuperKISS64(12345)
+	for _, lambda := range []float64{0.5, 4, 9.99, 10, 75, 1e6} {
+		mean, variance := meanVariance(n, func() float64 {
+			k := r.Poisson(lambda)
+			if k < 0 {
+				t.Fatalf("Poisson(%v) returned negative %v", lambda, k)
+			}
+			return float64(k)
+		})
+		if math.Abs(mean-lambda) > 0.01*lambda {
+			t.Errorf("Poisson(%v) mean is %v; want about %v",
+				lambda, mean, lambda)
+		}
+		if math.Abs(variance-lambda) > 0.02*lambda {
+			t.Errorf("Poisson(%v) variance is %v; want about %v",
+				lambda, variance, lambda)
+		}
+	}
+	if k := r.Poisson(0); k != 0 {
+		t.Errorf("Poisson(0) = %d; want 0", k)
+	}
+	for _, bad := range []float64{-1, math.NaN()} {
+		if !panics(func() { r.Poisson(bad) }) {
+			t.Errorf("Poisson(%v) did not panic", bad)
+		}
+	}
+}
+
+func TestBinomial(t *testing.T) {
+	const draws = 1000000
+	r := NewSuperKISS64(12345)
+	for _, c := range []struct {
+		n int64
+		p float64
+	}{
+		{10, 0.3}, {100, 0.25}, {1000, 0.5}, {1000, 0.97}, {1e9, 0.001},
+	} {
+		mean, variance := meanVariance(draws, func() float64 {
+			k := r.Binomial(c.n, c.p)
+			if k < 0 || k > c.n {
+				t.Fatalf("Binomial(%d, %v) returned %d", c.n, c.p, k)
+			}
+			return float64(k)
+		})
+		wantMean := float64(c.n) * c.p
+		wantVar := wantMean * (1 - c.p)
+		if math.Abs(mean-wantMean) > 0.01*wantMean {
+			t.Errorf("Binomial(%d, %v) mean is %v; want about %v",
+				c.n, c.p, mean, wantMean)
+		}
+		if math.Abs(variance-wantVar) > 0.02*wantVar {
+			t.Errorf("Binomial(%d, %v) variance is %v; want about %v",
+				c.n, c.p, variance, wantVar)
+		}
+	}
+	if k := r.Binomial(0, 0.5); k != 0 {
+		t.Errorf("Binomial(0, 0.5) = %d; want 0", k)
+	}
+	if k := r.Binomial(7, 1); k != 7 {
+		t.Errorf("Binomial(7, 1) = %d; want 7", k)
+	}
+	for _, bad := range []struct {
+		n int64
+		p float64
+	}{{-1, 0.5}, {5, -0.1}, {5, 1.1}, {5, math.NaN()}} {
+		if !panics(func() { r.Binomial(bad.n, bad.p) }) {
+			t.Errorf("Binomial(%d, %v) did not panic", bad.n, bad.p)
+		}
+	}
+}
+
+func TestGeometric(t *testing.T) {
+	const n = 1000000
+	r := NewSuperKISS64(12345)
+	for _, p := range []float64{0.01, 0.2, 0.5, 0.9} {
+		mean, _ := meanVariance(n, func() float64 {
+			k := r.Geometric(p)
+			if k < 0 {
+				t.Fatalf("Geometric(%v) returned negative %v", p, k)
+			}
+			return float64(k)
+		})
+		// The standard error of the mean is sqrt(1-p)/p/sqrt(n).
+		want := (1 - p) / p
+		if se := math.Sqrt(1-p) / p / math.Sqrt(n); math.Abs(mean-want) > 5*se {
+			t.Errorf("Geometric(%v) mean is %v; want about %v", p, mean, want)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		if k := r.Geometric(1); k != 0 {
+			t.Fatalf("Geometric(1) = %d; want 0", k)
+		}
+	}
+	for _, bad := range []float64{0, -0.5, 1.5, math.NaN()} {
+		if !panics(func() { r.Geometric(bad) }) {
+			t.Errorf("Geometric(%v) did not panic", bad)
+		}
+	}
+}
+
+func TestTriangular(t *testing.T) {
+	const n = 1000000
+	r := NewSuperKISS64(12345)
+	for _, c := range [][3]float64{
+		{0, 0.5, 1}, {-3, -3, 7}, {2, 9, 9}, {10, 12, 20},
+	} {
+		min, mode, max := c[0], c[1], c[2]
+		mean, _ := meanVariance(n, func() float64 {
+			x := r.Triangular(min, mode, max)
+			if x < min || x > max {
+				t.Fatalf("Triangular(%v, %v, %v) returned %v",
+					min, mode, max, x)
+			}
+			return x
+		})
+		want := (min + mode + max) / 3
+		if math.Abs(mean-want) > 0.002*(max-min) {
+			t.Errorf("Triangular(%v, %v, %v) mean is %v; want about %v",
+				min, mode, max, mean, want)
+		}
+	}
+	if x := r.Triangular(4, 4, 4); x != 4 {
+		t.Errorf("Triangular(4, 4, 4) = %v; want 4", x)
+	}
+	for _, bad := range [][3]float64{
+		{1, 0, 2}, {0, 3, 2}, {2, 1, 0}, {0, math.NaN(), 1},
+	} {
+		if !panics(func() { r.Triangular(bad[0], bad[1], bad[2]) }) {
+			t.Errorf("Triangular(%v, %v, %v) did not panic",
+				bad[0], bad[1], bad[2])
+		}
+	}
+}
+
+// forceNext adjusts r so its next Uint64 call returns want.
+func forceNext(r *SK64, want uint64) {
+	if r.Index >= QSIZE64 {
+		r.Uint64() // refill Q
+	}
+	c := r.Clone()
+	c.Q[c.Index] = 0
+	r.Q[r.Index] = want - c.Uint64()
+}
+
+func TestFloat64Full(t *testing.T) {
+	const draws = 1000000
+	r := NewSuperKISS64(29)
+	forceNext(r, 0)
+	if x := r.Float64Full(); x != 0 {
+		t.Errorf("Float64Full returned %v; want 0", x)
+	}
+	forceNext(r, math.MaxUint64)
+	if x, want := r.Float64Full(), math.Nextafter(1, 0); x != want {
+		t.Errorf("Float64Full returned %v; want %v", x, want)
+	}
+	bins := make([]int, 16)
+	for i := 0; i < draws; i++ {
+		x := r.Float64Full()
+		if x < 0 || x >= 1 {
+			t.Fatalf("Float64Full returned %v", x)
+		}
+		bins[int(x*16)]++
+	}
+	if p := binsPValue(bins); p < alpha || p > 1-alpha {
+		t.Errorf("Float64Full bins %v have p-value %v", bins, p)
+	}
+}
+
+func TestFillFloat64(t *testing.T) {
+	r := NewSuperKISS64(31)
+	r.Uint64() // start part way through Q
+	r2 := r.Clone()
+	for _, n := range []int{0, 1, 255, 256, 257, QSIZE64 + 3} {
+		dst := make([]float64, n)
+		r.FillFloat64(dst)
+		for i, got := range dst {
+			if want := r2.Float64(); got != want {
+				t.Fatalf("FillFloat64 n=%d: dst[%d] = %v; want %v",
+					n, i, got, want)
+			}
+		}
+	}
+	if !r.Equal(r2) {
+		t.Error("FillFloat64 left a different state than Float64 calls")
+	}
+}
+
+func TestReadBigEndian(t *testing.T) {
+	r, r2 := NewSuperKISS64(37), NewSuperKISS64(37)
+	le, be := make([]byte, 8*4+3), make([]byte, 8*4+3)
+	if n, err := r.Read(le); n != len(le) || err != nil {
+		t.Fatalf("Read returned %d, %v", n, err)
+	}
+	if n, err := r2.ReadBigEndian(be); n != len(be) || err != nil {
+		t.Fatalf("ReadBigEndian returned %d, %v", n, err)
+	}
+	for i := 0; i < 4; i++ {
+		lv := binary.LittleEndian.Uint64(le[8*i:])
+		bv := binary.BigEndian.Uint64(be[8*i:])
+		if lv != bv {
+			t.Errorf("word %d: little-endian %#x, big-endian %#x", i, lv, bv)
+		}
+	}
+	// The partial final word supplies low bytes to Read and high bytes to
+	// ReadBigEndian.
+	last := NewSuperKISS64(37)
+	last.Discard(4)
+	v := last.Uint64()
+	for i := 0; i < 3; i++ {
+		if le[32+i] != byte(v>>(8*i)) || be[32+i] != byte(v>>(56-8*i)) {
+			t.Errorf("tail byte %d: le %#x, be %#x, word %#x",
+				i, le[32+i], be[32+i], v)
+		}
+	}
+	if !r.Equal(r2) {
+		t.Error("Read and ReadBigEndian consumed different amounts of state")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	r := NewSuperKISS64(41)
+	fp := r.Fingerprint()
+	if c := r.Clone(); c.Fingerprint() != fp {
+		t.Error("Clone changed the fingerprint")
+	}
+	if fp2 := NewSuperKISS64(41).Fingerprint(); fp2 != fp {
+		t.Errorf("equal states have fingerprints %#x and %#x", fp, fp2)
+	}
+	r.Uint64()
+	if r.Fingerprint() == fp {
+		t.Error("Uint64 did not change the fingerprint")
+	}
+	fp = r.Fingerprint()
+	r.Q[QSIZE64-1] ^= 1
+	if r.Fingerprint() == fp {
+		t.Error("changing the last element of Q did not change the fingerprint")
+	}
+	if (*SK64)(nil).Fingerprint() != 0 {
+		t.Error("nil Fingerprint is not 0")
+	}
+}
+
+func TestAppendBinary(t *testing.T) {
+	r1, r2 := NewSuperKISS64(43), NewSuperKISS64(47)
+	r2.Uint32() // leave buffered bits in r2
+	buf := []byte("hdr")
+	buf, err := r1.AppendBinary(buf)
+	if err != nil {
+		t.Fatalf("AppendBinary returned error: %v", err)
+	}
+	buf, err = r2.AppendBinary(buf)
+	if err != nil {
+		t.Fatalf("AppendBinary returned error: %v", err)
+	}
+	n := binaryBytes(binaryVersion)
+	if string(buf[:3]) != "hdr" || len(buf) != 3+2*n {
+		t.Fatalf("AppendBinary produced %d bytes; want %d after header",
+			len(buf), 3+2*n)
+	}
+	for i, want := range []*SK64{r1, r2} {
+		var got SK64
+		if err := got.UnmarshalBinary(buf[3+i*n : 3+(i+1)*n]); err != nil {
+			t.Fatalf("state %d: UnmarshalBinary returned error: %v", i, err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("state %d did not round-trip", i)
+		}
+	}
+	if m, _ := r1.MarshalBinary(); !bytes.Equal(m, buf[3:3+n]) {
+		t.Error("MarshalBinary and AppendBinary differ")
+	}
+	if _, err := (*SK64)(nil).AppendBinary(nil); err == nil {
+		t.Error("AppendBinary with nil r returned nil error")
+	}
+}
+
+func TestNewParallel(t *testing.T) {
+	const goroutines = 64
+	firsts := make([]uint64, goroutines)
+	var wg sync.WaitGroup
+	for i := range firsts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			firsts[i] = NewParallel().Uint64()
+		}()
+	}
+	wg.Wait()
+	seen := make(map[uint64]bool)
+	for _, v := range firsts {
+		if seen[v] {
+			t.Fatalf("NewParallel generators produced duplicate first "+
+				"output %#x", v)
+		}
+		seen[v] = true
+	}
+}
+
+func TestFillGeneric(t *testing.T) {
+	r, r2 := NewSuperKISS64(53), NewSuperKISS64(53)
+	u16 := make([]uint16, 2000000)
+	Fill(r, u16)
+	for i := 0; i < 8; i += 4 {
+		v := r2.Uint64()
+		for k := 0; k < 4; k++ {
+			if u16[i+k] != uint16(v>>(16*k)) {
+				t.Fatalf("u16[%d] = %#x; want %#x",
+					i+k, u16[i+k], uint16(v>>(16*k)))
+			}
+		}
+	}
+	seen := make([]bool, 1<<16)
+	for _, v := range u16 {
+		seen[v] = true
+	}
+	for v, ok := range seen {
+		if !ok {
+			t.Fatalf("Fill[uint16] never produced %#x", v)
+		}
+	}
+	i32 := make([]int32, 100001)
+	Fill(r, i32)
+	or, and := int32(0), int32(-1)
+	neg := 0
+	for _, v := range i32 {
+		or, and = or|v, and&v
+		if v < 0 {
+			neg++
+		}
+	}
+	if or != -1 || and != 0 {
+		t.Errorf("Fill[int32] bits: or %#x, and %#x; want all bits to vary",
+			or, and)
+	}
+	if p := binsPValue([]int{neg, len(i32) - neg}); p < alpha || p > 1-alpha {
+		t.Errorf("Fill[int32] produced %d negative values in %d", neg, len(i32))
+	}
+	r, r2 = NewSuperKISS64(59), NewSuperKISS64(59)
+	u64 := make([]uint64, 10)
+	Fill(r, u64)
+	for i, v := range u64 {
+		if want := r2.Uint64(); v != want {
+			t.Errorf("Fill[uint64] element %d = %#x; want %#x", i, v, want)
+		}
+	}
+}
+
+func TestFillBytes(t *testing.T) {
+	r, r2 := NewSuperKISS64(61), NewSuperKISS64(61)
+	for _, n := range []int{0, 1, 7, 8, 1<<20 + 5} {
+		p := make([]byte, n)
+		for i := range p {
+			p[i] = 0xA5
+		}
+		r.Fill(p)
+		want := make([]byte, n)
+		r2.Read(want)
+		if !bytes.Equal(p, want) {
+			t.Fatalf("Fill of %d bytes differs from Read", n)
+		}
+		// Every byte must have been written: a run of 0xA5 bytes left over
+		// would be vanishingly unlikely.
+		if n > 8 && bytes.HasSuffix(p, []byte{0xA5, 0xA5, 0xA5, 0xA5, 0xA5}) {
+			t.Fatalf("Fill of %d bytes left the end of p unwritten", n)
+		}
+	}
+}
+
+func TestPool(t *testing.T) {
+	var p Pool
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, 64)
+			for i := 0; i < 50; i++ {
+				r := p.Get()
+				if !r.Seeded {
+					t.Error("Pool.Get returned an unseeded generator")
+				}
+				r.Read(buf)
+				r.Uint64()
+				p.Put(r)
+			}
+		}()
+	}
+	wg.Wait()
+	p.Put(nil)
+	if r := p.Get(); r == nil {
+		t.Error("Pool.Get returned nil")
+	}
+}
+
+func TestRandomString(t *testing.T) {
+	r := NewSuperKISS64(67)
+	s := r.AlphaNumeric(100000)
+	if len(s) != 100000 {
+		t.Fatalf("AlphaNumeric(100000) returned %d bytes", len(s))
+	}
+	bins := make([]int, len(alphaNumeric))
+	for _, c := range s {
+		i := strings.IndexRune(alphaNumeric, c)
+		if i < 0 {
+			t.Fatalf("AlphaNumeric returned %q", c)
+		}
+		bins[i]++
+	}
+	if p := binsPValue(bins); p < alpha || p > 1-alpha {
+		t.Errorf("AlphaNumeric character counts have p-value %v", p)
+	}
+	a := NewSuperKISS64(71).AlphaNumeric(20)
+	if b := NewSuperKISS64(71).AlphaNumeric(20); a != b {
+		t.Errorf("AlphaNumeric is not reproducible: %q and %q", a, b)
+	}
+	const charset = "αβγ-"
+	s = r.RandomString(50, charset)
+	if n := len([]rune(s)); n != 50 {
+		t.Errorf("RandomString(50, %q) returned %d runes", charset, n)
+	}
+	for _, c := range s {
+		if !strings.ContainsRune(charset, c) {
+			t.Fatalf("RandomString returned %q not in %q", c, charset)
+		}
+	}
+	if s := r.RandomString(0, charset); s != "" {
+		t.Errorf("RandomString(0, ...) returned %q", s)
+	}
+	if !panics(func() { r.RandomString(5, "") }) {
+		t.Error("RandomString with empty charset did not panic")
+	}
+	if !panics(func() { r.RandomString(-1, "ab") }) {
+		t.Error("RandomString(-1, ...) did not panic")
+	}
+}
+
+func TestUUID(t *testing.T) {
+	r := NewSuperKISS64(73)
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		u := r.UUID()
+		if len(u) != 36 ||
+			u[8] != '-' || u[13] != '-' || u[18] != '-' || u[23] != '-' {
+			t.Fatalf("UUID %q is not in 8-4-4-4-12 form", u)
+		}
+		for j, c := range u {
+			if j == 8 || j == 13 || j == 18 || j == 23 {
+				continue
+			}
+			if !strings.ContainsRune("0123456789abcdef", c) {
+				t.Fatalf("UUID %q has non-hex character %q", u, c)
+			}
+		}
+		if u[14] != '4' {
+			t.Fatalf("UUID %q version nibble is %c; want 4", u, u[14])
+		}
+		if !strings.ContainsRune("89ab", rune(u[19])) {
+			t.Fatalf("UUID %q variant nibble is %c; want 8, 9, a or b",
+				u, u[19])
+		}
+		if seen[u] {
+			t.Fatalf("UUID %q repeated", u)
+		}
+		seen[u] = true
+	}
+}
+
+// unXorShift inverts XorShift by undoing each of its three steps.
+func unXorShift(x uint64) uint64 {
+	x ^= x << 43
+	x ^= x >> 17 // a right shift of 17 needs 4 terms to undo
+	x ^= x >> 34
+	x ^= x << 13 // a left shift of 13 needs 5 terms to undo
+	x ^= x << 26
+	x ^= x << 52
+	return x
+}
+
+func TestXorShift(t *testing.T) {
+	orbit := []uint64{0x0100080000002001, 0x0010000000000201,
+		0x0101080800402221, 0x0001000000040023}
+	x := uint64(1)
+	for i, want := range orbit {
+		if x = XorShift(x); x != want {
+			t.Fatalf("XorShift step %d from 1 gave %#x; want %#x", i+1, x, want)
+		}
+	}
+	if XorShift(0) != 0 {
+		t.Error("XorShift(0) != 0")
+	}
+	r := NewSuperKISS64(83)
+	for i := 0; i < 100000; i++ {
+		v := r.Uint64() | 1<<uint(i%64) // nonzero
+		y := XorShift(v)
+		if y == 0 {
+			t.Fatalf("XorShift(%#x) == 0", v)
+		}
+		if u := unXorShift(y); u != v {
+			t.Fatalf("XorShift is not invertible at %#x: got back %#x", v, u)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := NewSuperKISS64(89).Validate(); err != nil {
+		t.Errorf("Validate of a fresh generator returned %v", err)
+	}
+	for _, c := range []struct {
+		name  string
+		spoil func(r *SK64)
+		want  string
+	}{
+		{"unseeded", func(r *SK64) { r.Seeded = false }, "Seeded"},
+		{"short Q", func(r *SK64) { r.Q = r.Q[:10] }, "Q values"},
+		{"Index", func(r *SK64) { r.Index = QSIZE64 + 1 }, "Index"},
+		{"zero Xs", func(r *SK64) { r.Xs = 0 }, "Xs 0"},
+		{"NBits", func(r *SK64) { r.NBits = 65 }, "NBits"},
+	} {
+		r := NewSuperKISS64(89)
+		c.spoil(r)
+		err := r.Validate()
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: Validate returned %v; want error mentioning %q",
+				c.name, err, c.want)
+		}
+	}
+	if (*SK64)(nil).Validate() == nil {
+		t.Error("Validate of nil returned nil")
+	}
+}
+
+func TestNonzeroXs(t *testing.T) {
+	// No seed slice can zero Xs in SeedFromSlice, since XorShift maps
+	// nonzero values to nonzero values, so try degenerate slices and
+	// exercise the guard directly.
+	r := NewSuperKISS64(97)
+	for _, s := range [][]uint64{{0}, make([]uint64, QSIZE64), {^uint64(0)}} {
+		r.SeedFromSlice(s)
+		if r.Xs == 0 {
+			t.Errorf("SeedFromSlice(%d words) left Xs == 0", len(s))
+		}
+	}
+	r.Xs = 0
+	r.nonzeroXs()
+	if r.Xs == 0 {
+		t.Fatal("nonzeroXs left Xs == 0")
+	}
+	seen := make(map[uint64]bool)
+	for i := 0; i < 1000; i++ {
+		seen[r.Uint64()] = true
+	}
+	if len(seen) != 1000 {
+		t.Errorf("repaired generator produced only %d distinct values in 1000",
+			len(seen))
+	}
+}
+
+func TestReadMatchesUint64(t *testing.T) {
+	r := NewSuperKISS64(101)
+	r2 := r.Clone()
+	for _, n := range []int{
+		0, 3, 8, 17, 8 * 256, 8*256 + 8, 8*QSIZE64 + 5, 1 << 20,
+	} {
+		p := make([]byte, n)
+		r.Read(p)
+		want := make([]byte, 0, n+8)
+		for len(want) < n {
+			want = binary.LittleEndian.AppendUint64(want, r2.Uint64())
+		}
+		if !bytes.Equal(p, want[:n]) {
+			t.Fatalf("Read of %d bytes differs from Uint64 packing", n)
+		}
+	}
+	if !r.Equal(r2) {
+		t.Error("Read and Uint64 calls left different states")
+	}
+}
+
+func TestSeedFast(t *testing.T) {
+	r, r2 := NewSuperKISS64(1), NewSuperKISS64(1)
+	r.SeedFast(103)
+	r2.SeedFast(103)
+	if !r.Equal(r2) {
+		t.Error("SeedFast is not deterministic")
+	}
+	// Seed is SeedFast followed by the warm-up.
+	r.Discard(QSIZE64 * 4)
+	r2.Seed(103)
+	if !r.Equal(r2) {
+		t.Error("SeedFast plus warm-up differs from Seed")
+	}
+	r.SeedFast(0)
+	r2.Seed(0)
+	if !r.Equal(r2) {
+		t.Error("SeedFast(0) differs from Seed(0)")
+	}
+}
+
+func TestMarshalText(t *testing.T) {
+	r := NewSuperKISS64(107)
+	r.Bool() // leave buffered bits
+	text, err := r.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText returned error: %v", err)
+	}
+	if bytes.ContainsAny(text, "\n=+/") {
+		t.Error("MarshalText output is not single-line unpadded " +
+			"URL-safe base64")
+	}
+	var r2 SK64
+	if err := r2.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText returned error: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if a, b := r.Uint64(), r2.Uint64(); a != b {
+			t.Fatalf("output %d after text round trip: %#x != %#x", i, a, b)
+		}
+	}
+	saved := r2.Clone()
+	for _, bad := range [][]byte{text[:len(text)-4], []byte("not*base64"),
+		[]byte("WFhYWA")} {
+		if err := r2.UnmarshalText(bad); err == nil {
+			t.Errorf("UnmarshalText(%.10q...) returned nil error", bad)
+		}
+	}
+	if !r2.Equal(saved) {
+		t.Error("failed UnmarshalText changed r")
+	}
+	// XML state files keep their element-per-field form.
+	var buf bytes.Buffer
+	if err := r.WriteState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("<SK64><Carry>")) {
+		t.Errorf("WriteState no longer writes fields: %.80q", buf.Bytes())
+	}
+}
+
+func TestPeek(t *testing.T) {
+	r := NewSuperKISS64(109) // Index == QSIZE64, so a refill is due
+	for i := 0; i < 2*QSIZE64+2; i++ {
+		var before *SK64
+		if i%QSIZE64 == 0 || i%997 == 0 { // cloning every step is slow
+			before = r.Clone()
+		}
+		p := r.Peek()
+		if p2 := r.Peek(); p2 != p {
+			t.Fatalf("consecutive Peeks returned %#x and %#x", p, p2)
+		}
+		if before != nil && !r.Equal(before) {
+			t.Fatalf("Peek changed the state at step %d", i)
+		}
+		if u := r.Uint64(); u != p {
+			t.Fatalf("step %d: Peek returned %#x but Uint64 returned %#x",
+				i, p, u)
+		}
+	}
+	var z SK64
+	z.Q = make([]uint64, QSIZE64)
+	if p, u := z.Peek(), NewSuperKISS64(1).Uint64(); p != u || z.Seeded {
+		t.Errorf("Peek of unseeded generator returned %#x, want %#x, "+
+			"or seeded it", p, u)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	const buckets, samples = 50, 1000000
+	r := NewSuperKISS64(113)
+	counts := r.Histogram(buckets, samples)
+	if len(counts) != buckets {
+		t.Fatalf("Histogram returned %d buckets; want %d", len(counts), buckets)
+	}
+	total := 0
+	for i, c := range counts {
+		total += c
+		// The standard deviation of each count is about 140, or 0.7%.
+		if math.Abs(float64(c)-samples/buckets) > 0.05*samples/buckets {
+			t.Errorf("bucket %d has %d; want about %d", i, c, samples/buckets)
+		}
+	}
+	if total != samples {
+		t.Errorf("Histogram counts total %d; want %d", total, samples)
+	}
+	if !panics(func() { r.Histogram(0, 10) }) ||
+		!panics(func() { r.Histogram(3, -1) }) {
+		t.Error("Histogram did not panic on bad arguments")
+	}
+}
+
+func TestSeed128(t *testing.T) {
+	first := func(hi, lo int64) [4]uint64 {
+		r := NewSuperKISS64(1)
+		r.Seed128(hi, lo)
+		return [4]uint64{r.Uint64(), r.Uint64(), r.Uint64(), r.Uint64()}
+	}
+	seen := make(map[[4]uint64][2]int64)
+	pairs := [][2]int64{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {-1, -1},
+		{math.MinInt64, math.MaxInt64}}
+	for _, p := range pairs {
+		f := first(p[0], p[1])
+		if q, dup := seen[f]; dup {
+			t.Errorf("Seed128%v and Seed128%v produce the same sequence", p, q)
+		}
+		seen[f] = p
+		if f != first(p[0], p[1]) {
+			t.Errorf("Seed128%v is not reproducible", p)
+		}
+	}
+	// hi == 521288629546311 would zero Xs, so it changes Carry instead.
+	if first(521288629546311, 5) == first(0, 5) {
+		t.Error("Seed128(521288629546311, 5) is the same as Seed128(0, 5)")
+	}
+	r := NewSuperKISS64(1)
+	r.Seed128(521288629546311, 5)
+	if err := r.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewSuperKISS64FromSliceCopy(t *testing.T) {
+	s := []uint64{1, 2, 3, 0xdeadbeef}
+	want := NewSuperKISS64FromSlice(s).Uint64()
+	r := NewSuperKISS64FromSliceCopy(s)
+	clear(s)
+	if got := r.Uint64(); got != want {
+		t.Errorf("NewSuperKISS64FromSliceCopy first output %#x; want %#x",
+			got, want)
+	}
+}
+
+func TestWipe(t *testing.T) {
+	r := NewSuperKISS64Rand()
+	r.Bool()
+	q := r.Q
+	r.Wipe()
+	if r.Carry != 0 || r.Xcng != 0 || r.Xs != 0 || r.Index != 0 ||
+		r.Bits != 0 || r.NBits != 0 || r.Seeded {
+		t.Errorf("Wipe left state %v", r)
+	}
+	for i, v := range q {
+		if v != 0 {
+			t.Fatalf("Wipe left Q[%d] = %#x", i, v)
+		}
+	}
+	if got, want := r.Uint64(), NewSuperKISS64(1).Uint64(); got != want {
+		t.Errorf("Uint64 after Wipe returned %#x; want Seed(1)'s %#x",
+			got, want)
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	p := Period()
+	if digits := len(p.String()); digits != 397525 {
+		t.Errorf("Period has %d decimal digits; want 397525", digits)
+	}
+	if p.Bit(1320480) != 1 || p.TrailingZeroBits() != 1320480 {
+		t.Error("Period is not 5*2^1320480*(2^64-1)")
+	}
+	if StateCount().Cmp(p) != 0 {
+		t.Error("StateCount differs from Period")
+	}
+	p.SetInt64(0)
+	if Period().Sign() == 0 {
+		t.Error("changing a returned Period changed later results")
+	}
+}
+
+func TestShuffleSeed(t *testing.T) {
+	shuffled := func(seed int64) []int {
+		s := make([]int, 50)
+		for i := range s {
+			s[i] = i
+		}
+		ShuffleSeed(seed, len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+		return s
+	}
+	a := shuffled(127)
+	if !isPerm(a) {
+		t.Fatalf("ShuffleSeed produced %v, not a permutation", a)
+	}
+	if !slices.Equal(a, shuffled(127)) {
+		t.Error("ShuffleSeed with the same seed gave different orders")
+	}
+	if slices.Equal(a, shuffled(131)) {
+		t.Error("ShuffleSeed with different seeds gave the same order")
+	}
+}
+
+func TestLoadStateSniffsGzip(t *testing.T) {
+	r := NewSuperKISS64(137)
+	dir := t.TempDir()
+	for _, c := range []struct {
+		name  string
+		write func(*SK64, io.Writer) error
+	}{
+		{"gzipped.xml", (*SK64).WriteStateGzip},
+		{"plain.xml.gz", (*SK64).WriteState},
+	} {
+		fName := filepath.Join(dir, c.name)
+		f, err := os.Create(fName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = errors.Join(c.write(r, f), f.Close())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var z SK64
+		if err := z.LoadState(fName); err != nil {
+			t.Errorf("LoadState(%s) returned error: %v", c.name, err)
+		} else if !z.Equal(r) {
+			t.Errorf("LoadState(%s) loaded a different state", c.name)
+		}
+	}
+}
+
+func TestLimitReader(t *testing.T) {
+	const n = 100003
+	got, err := io.ReadAll(NewSuperKISS64(139).LimitReader(n))
+	if err != nil || len(got) != n {
+		t.Fatalf("ReadAll of LimitReader(%d) returned %d bytes, %v",
+			n, len(got), err)
+	}
+	again, _ := io.ReadAll(NewSuperKISS64(139).LimitReader(n))
+	if !bytes.Equal(got, again) {
+		t.Error("LimitReader output is not reproducible")
+	}
+	// A single Read of the whole limit matches SK64.Read.
+	p := make([]byte, n)
+	NewSuperKISS64(139).LimitReader(n).Read(p)
+	want := NewSuperKISS64(139).Bytes(n)
+	if !bytes.Equal(p, want) {
+		t.Error("LimitReader bytes differ from Read")
+	}
+	b, err := io.ReadAll(NewSuperKISS64(139).LimitReader(0))
+	if len(b) != 0 || err != nil {
+		t.Errorf("LimitReader(0) read %d bytes, %v", len(b), err)
+	}
+}
+
+func TestDirichlet(t *testing.T) {
+	const draws = 100000
+	r := NewSuperKISS64(149)
+	for _, alpha := range [][]float64{
+		{1, 1, 1, 1}, {0.2, 0.2, 0.2}, {2, 5, 3},
+	} {
+		means := make([]float64, len(alpha))
+		total := 0.0
+		for _, a := range alpha {
+			total += a
+		}
+		for i := 0; i < draws; i++ {
+			x := r.Dirichlet(alpha)
+			if len(x) != len(alpha) {
+				t.Fatalf("Dirichlet(%v) returned %d values", alpha, len(x))
+			}
+			sum := 0.0
+			for j, v := range x {
+				if v < 0 {
+					t.Fatalf("Dirichlet(%v) returned negative %v", alpha, v)
+				}
+				sum += v
+				means[j] += v / draws
+			}
+			if math.Abs(sum-1) > 1e-12 {
+				t.Fatalf("Dirichlet(%v) values sum to %v", alpha, sum)
+			}
+		}
+		for j, m := range means {
+			if want := alpha[j] / total; math.Abs(m-want) > 0.005 {
+				t.Errorf("Dirichlet(%v) component %d mean %v; want about %v",
+					alpha, j, m, want)
+			}
+		}
+	}
+	for _, bad := range [][]float64{nil, {1, 0}, {1, -2}, {math.NaN()}} {
+		if !panics(func() { r.Dirichlet(bad) }) {
+			t.Errorf("Dirichlet(%v) did not panic", bad)
+		}
+	}
+}
+
+func TestAliasTable(t *testing.T) {
+	const draws = 1000000
+	r := NewSuperKISS64(151)
+	for _, weights := range [][]float64{
+		{0, 1, 2, 0, 7, 0}, {5}, {1, 1, 1}, {1e-3, 1, 100, 0.5, 3, 3, 3, 0.25},
+	} {
+		a := NewAliasTable(weights)
+		total := 0.0
+		for _, w := range weights {
+			total += w
+		}
+		counts := make([]int, len(weights))
+		for i := 0; i < draws; i++ {
+			counts[a.Sample(r)]++
+		}
+		for i, w := range weights {
+			got := float64(counts[i]) / draws
+			want := w / total
+			if math.Abs(got-want) > 0.003 || (w == 0 && got != 0) {
+				t.Errorf("weights %v: index %d chosen with frequency "+
+					"%v; want %v",
+					weights, i, got, want)
+			}
+		}
+	}
+	for _, w := range [][]float64{
+		nil, {0, 0}, {1, -1}, {math.NaN()}, {math.Inf(1)},
+	} {
+		if !panics(func() { NewAliasTable(w) }) {
+			t.Errorf("NewAliasTable(%v) did not panic", w)
+		}
+	}
+}
+
+func TestDefault(t *testing.T) {
+	if Default() != Default() {
+		t.Fatal("Default returned different generators")
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				Uint64()
+				if x := Float64(); x < 0 || x >= 1 {
+					t.Errorf("Float64 returned %v", x)
+				}
+				if n := Intn(10); n < 0 || n >= 10 {
+					t.Errorf("Intn(10) returned %d", n)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if !panics(func() { Intn(0) }) {
+		t.Error("Intn(0) did not panic")
+	}
+	Uint64() // the panic must not leave Default locked
+}
+
+func TestReadUntil(t *testing.T) {
+	r, r2 := NewSuperKISS64(157), NewSuperKISS64(157)
+	p := make([]byte, 100000)
+	n := r.ReadUntil(p, func(b byte) bool { return b == 0x42 })
+	if n == len(p) || p[n-1] != 0x42 {
+		t.Fatalf("ReadUntil returned %d; p[n-1] = %#x", n, p[n-1])
+	}
+	for i := 0; i < n; i++ {
+		b, _ := r2.ReadByte()
+		if p[i] != b {
+			t.Fatalf("p[%d] = %#x; want %#x", i, p[i], b)
+		}
+		if i < n-1 && b == 0x42 {
+			t.Fatalf("ReadUntil did not stop at index %d", i)
+		}
+	}
+	if n := r.ReadUntil(p[:10], func(byte) bool { return false }); n != 10 {
+		t.Errorf("ReadUntil with no stop returned %d; want 10", n)
+	}
+	if n := r.ReadUntil(nil, func(byte) bool { return true }); n != 0 {
+		t.Errorf("ReadUntil with empty p returned %d", n)
+	}
+}
+
+func TestCount(t *testing.T) {
+	r := NewSuperKISS64(163)
+	if n := r.Count(); n != 0 {
+		t.Fatalf("Count after seeding = %d; want 0", n)
+	}
+	for i := 0; i < 1000; i++ {
+		r.Uint64()
+	}
+	r.FillUint64(make([]uint64, QSIZE64+1))
+	r.Skip(77)
+	r.Float64()
+	want := uint64(1000 + QSIZE64 + 1 + 77 + 1)
+	if n := r.Count(); n != want {
+		t.Errorf("Count = %d; want %d", n, want)
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"count.xml", "count.bin"} {
+		fName := filepath.Join(dir, name)
+		save, load := (*SK64).SaveState, (*SK64).LoadState
+		if strings.HasSuffix(name, ".bin") {
+			save, load = (*SK64).SaveStateBinary, (*SK64).LoadStateBinary
+		}
+		if err := save(r, fName); err != nil {
+			t.Fatal(err)
+		}
+		var z SK64
+		if err := load(&z, fName); err != nil {
+			t.Fatal(err)
+		}
+		if n := z.Count(); n != want {
+			t.Errorf("Count after loading %s = %d; want %d", name, n, want)
+		}
+	}
+	r.ResetCount()
+	if n := r.Count(); n != 0 {
+		t.Errorf("Count after ResetCount = %d; want 0", n)
+	}
+	r.SeedFromSlice([]uint64{1})
+	r.Uint64()
+	if n := r.Count(); n != 1 {
+		t.Errorf("Count after SeedFromSlice and one Uint64 = %d; want 1", n)
+	}
+}
+
+func TestSeedMix(t *testing.T) {
+	first := func(fixed int64, extra ...uint64) uint64 {
+		r := NewSuperKISS64(1)
+		r.SeedMix(fixed, extra...)
+		return r.Uint64()
+	}
+	seen := make(map[uint64]string)
+	for _, c := range []struct {
+		fixed int64
+		extra []uint64
+	}{
+		{0, nil}, {1, nil}, {0, []uint64{0}}, {0, []uint64{1}},
+		{0, []uint64{0, 0}}, {7, []uint64{1, 2}}, {7, []uint64{2, 1}},
+	} {
+		name := fmt.Sprint(c.fixed, c.extra)
+		v := first(c.fixed, c.extra...)
+		if other, dup := seen[v]; dup {
+			t.Errorf("SeedMix(%s) and SeedMix(%s) give the same sequence",
+				name, other)
+		}
+		seen[v] = name
+		if first(c.fixed, c.extra...) != v {
+			t.Errorf("SeedMix(%s) is not reproducible", name)
+		}
+	}
+}
+
+func TestNewSuperKISS64FromHash(t *testing.T) {
+	digest := func(s string) hash.Hash64 {
+		h := fnv.New64a()
+		io.WriteString(h, s)
+		return h
+	}
+	h := digest("experiment 42")
+	sum := h.Sum64()
+	a := NewSuperKISS64FromHash(h)
+	if h.Sum64() != sum {
+		t.Error("NewSuperKISS64FromHash modified h")
+	}
+	b := NewSuperKISS64FromHash(digest("experiment 42"))
+	c := NewSuperKISS64FromHash(digest("experiment 43"))
+	for i := 0; i < 100; i++ {
+		if x, y := a.Uint64(), b.Uint64(); x != y {
+			t.Fatalf("equal digests gave %#x and %#x at index %d", x, y, i)
+		}
+	}
+	if a.Uint64() == c.Uint64() {
+		t.Error("different digests gave the same output")
+	}
+}
+
+func TestXorSource(t *testing.T) {
+	a, b := NewSuperKISS64(167), NewSuperKISS64(173)
+	x := NewXorSource(a.Clone(), b.Clone())
+	for i := 0; i < 100; i++ {
+		if got, want := x.Uint64(), a.Uint64()^b.Uint64(); got != want {
+			t.Fatalf("XorSource.Uint64 = %#x; want %#x", got, want)
+		}
+	}
+	rng := rand.New(x)
+	if n := rng.Intn(10); n < 0 || n >= 10 {
+		t.Errorf("rand.New(XorSource).Intn(10) = %d", n)
+	}
+	x.Seed(1)
+	a.Seed(1)
+	b.SeedMix(1, 1)
+	if a.Equal(b) {
+		t.Fatal("XorSource.Seed gave both generators the same state")
+	}
+	for i := 0; i < 100; i++ {
+		if got, want := x.Uint64(), a.Uint64()^b.Uint64(); got != want {
+			t.Fatalf("after Seed, XorSource.Uint64 = %#x; want %#x",
+				got, want)
+		}
+	}
+	p := make([]byte, 13)
+	x.Read(p)
+	want := binary.LittleEndian.AppendUint64(nil, a.Uint64()^b.Uint64())
+	want = binary.LittleEndian.AppendUint64(want, a.Uint64()^b.Uint64())
+	if !bytes.Equal(p, want[:13]) {
+		t.Errorf("XorSource.Read gave %x; want %x", p, want[:13])
+	}
+	pValueTest(x, t)
+}
+
+// legacySK64 has SK64's fields without its methods, so encoding/xml
+// writes it with one Q element per value, as older versions did.
+type legacySK64 SK64
+
+func TestMarshalXML(t *testing.T) {
+	r := NewSuperKISS64(179)
+	r.Bool()
+	var compact, legacy bytes.Buffer
+	if err := r.WriteState(&compact); err != nil {
+		t.Fatal(err)
+	}
+	e := xml.NewEncoder(&legacy)
+	start := xml.StartElement{Name: xml.Name{Local: "SK64"}}
+	err := e.EncodeElement((*legacySK64)(r), start)
+	if err = errors.Join(err, e.Close()); err != nil {
+		t.Fatal(err)
+	}
+	const q64 = `<Q encoding="base64">`
+	if n := bytes.Count(compact.Bytes(), []byte("<Q")); n != 1 ||
+		!bytes.Contains(compact.Bytes(), []byte(q64)) {
+		t.Errorf("WriteState wrote %d Q elements; want 1 base64 element", n)
+	}
+	// The decimal form written by the previous version.
+	var decimal bytes.Buffer
+	i := bytes.Index(compact.Bytes(), []byte(q64))
+	j := bytes.Index(compact.Bytes(), []byte("</Q>"))
+	decimal.Write(compact.Bytes()[:i])
+	decimal.WriteString("<Q>")
+	for k, v := range r.Q {
+		if k > 0 {
+			decimal.WriteByte(' ')
+		}
+		fmt.Fprint(&decimal, v)
+	}
+	decimal.Write(compact.Bytes()[j:])
+	if compact.Len() > decimal.Len()*55/100 {
+		t.Errorf("base64 XML is %d bytes; decimal XML is %d",
+			compact.Len(), decimal.Len())
+	}
+	for _, c := range []struct {
+		name string
+		data []byte
+	}{
+		{"base64", compact.Bytes()},
+		{"decimal", decimal.Bytes()},
+		{"legacy", legacy.Bytes()},
+	} {
+		var z SK64
+		if err := z.ReadState(bytes.NewReader(c.data)); err != nil {
+			t.Fatalf("ReadState of %s XML returned error: %v", c.name, err)
+		}
+		if !z.Equal(r) {
+			t.Errorf("%s XML did not round-trip", c.name)
+		}
+	}
+	// A state embedded in another XML document also round-trips.
+	type doc struct {
+		Name string
+		Gen  *SK64
+	}
+	b, err := xml.Marshal(doc{"run", r})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d doc
+	err = xml.Unmarshal(b, &d)
+	if err != nil || !d.Gen.Equal(r) || d.Name != "run" {
+		t.Errorf("embedded state did not round-trip: %v", err)
+	}
+	for _, bad := range [][]byte{
+		bytes.Replace(compact.Bytes(), []byte(q64), []byte(q64+"!"), 1),
+		bytes.Replace(compact.Bytes(), []byte(q64), []byte(q64+"AAAA"), 1),
+		bytes.Replace(compact.Bytes(), []byte("base64"), []byte("hex"), 1),
+		bytes.Replace(decimal.Bytes(), []byte("<Q>"), []byte("<Q>x "), 1),
+	} {
+		if err := new(SK64).ReadState(bytes.NewReader(bad)); err == nil {
+			t.Error("ReadState accepted a bad Q")
+		}
+	}
+}
+
+func TestSaveStateCompact(t *testing.T) {
+	r := NewSuperKISS64Rand()
+	r.Uint32()
+	dir := t.TempDir()
+	compact := filepath.Join(dir, "state.sk64")
+	if err := r.SaveStateCompact(compact); err != nil {
+		t.Fatalf("SaveStateCompact returned error: %v", err)
+	}
+	var z SK64
+	if err := z.LoadStateCompact(compact); err != nil {
+		t.Fatalf("LoadStateCompact returned error: %v", err)
+	}
+	if !z.Equal(r) {
+		t.Error("SaveStateCompact state did not round-trip")
+	}
+	// Q's 165 KB of pseudorandom values does not compress, so the file
+	// is about 166 KB, no bigger than SaveState's gzip'ped XML.
+	gz := filepath.Join(dir, "state.xml.gz")
+	if err := r.SaveState(gz); err != nil {
+		t.Fatal(err)
+	}
+	ci, err1 := os.Stat(compact)
+	gi, err2 := os.Stat(gz)
+	if err := errors.Join(err1, err2); err != nil {
+		t.Fatal(err)
+	}
+	if ci.Size() > gi.Size() || ci.Size() > 170000 {
+		t.Errorf("compact state is %d bytes; gzip'ped XML is %d",
+			ci.Size(), gi.Size())
+	}
+}
+
+func TestValuesFloats(t *testing.T) {
+	r, r2 := NewSuperKISS64(181), NewSuperKISS64(181)
+	i := 0
+	for v := range r.Values() {
+		if want := r2.Uint64(); v != want {
+			t.Fatalf("Values yielded %#x at %d; want %#x", v, i, want)
+		}
+		if i++; i == 10 {
+			break
+		}
+	}
+	i = 0
+	for x := range r.Floats() {
+		if want := r2.Float64(); x != want {
+			t.Fatalf("Floats yielded %v at %d; want %v", x, i, want)
+		}
+		if i++; i == 10 {
+			break
+		}
+	}
+	if !r.Equal(r2) {
+		t.Error("breaking out of the iterators drew extra values")
+	}
+}
+
+func TestIndices(t *testing.T) {
+	r, r2 := NewSuperKISS64(191), NewSuperKISS64(191)
+	var got []int
+	for i := range r.Indices(7, 1000) {
+		if i < 0 || i >= 7 {
+			t.Fatalf("Indices(7, ...) yielded %d", i)
+		}
+		got = append(got, i)
+	}
+	if len(got) != 1000 {
+		t.Errorf("Indices(7, 1000) yielded %d indexes", len(got))
+	}
+	for k, i := range got {
+		if want := r2.Intn(7); i != want {
+			t.Fatalf("index %d is %d; want %d", k, i, want)
+		}
+	}
+	for range r.Indices(5, 0) {
+		t.Fatal("Indices(5, 0) yielded an index")
+	}
+	if !panics(func() { r.Indices(0, 3) }) ||
+		!panics(func() { r.Indices(3, -1) }) {
+		t.Error("Indices did not panic on bad arguments")
+	}
+}
+
+func TestLoadSaveErrorsNameFile(t *testing.T) {
+	r := NewSuperKISS64(193)
+	dir := t.TempDir()
+	fName := filepath.Join(dir, "truncated.xml.gz")
+	if err := r.SaveState(fName); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(fName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(fName, b[:len(b)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var z SK64
+	err = z.LoadState(fName)
+	if err == nil || !strings.Contains(err.Error(), fName) {
+		t.Errorf("LoadState of truncated file returned %v; want error "+
+			"naming %s", err, fName)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("LoadState error %v does not wrap io.ErrUnexpectedEOF", err)
+	}
+	missing := filepath.Join(dir, "no-such-dir", "state.bin")
+	for _, err := range []error{
+		r.SaveState(missing), r.SaveStateBinary(missing),
+		r.SaveStateCompact(missing), z.LoadStateBinary(missing),
+	} {
+		if !errors.Is(err, fs.ErrNotExist) ||
+			!strings.Contains(err.Error(), missing) {
+			t.Errorf("got error %v; want a wrapped fs.ErrNotExist naming %s",
+				err, missing)
+		}
+	}
+}
+
+func TestDrawRemove(t *testing.T) {
+	r := NewSuperKISS64(197)
+	deck := make([]int, 52)
+	for i := range deck {
+		deck[i] = i
+	}
+	dealt := make([]int, 0, 52)
+	for len(deck) > 0 {
+		n := len(deck)
+		dealt = append(dealt, DrawRemove(r, &deck))
+		if len(deck) != n-1 {
+			t.Fatalf("DrawRemove left %d cards; want %d", len(deck), n-1)
+		}
+	}
+	if !isPerm(dealt) {
+		t.Errorf("DrawRemove dealt %v, not each card once", dealt)
+	}
+	if !panics(func() { DrawRemove(r, &deck) }) {
+		t.Error("DrawRemove of an empty deck did not panic")
+	}
+}
+
+func TestFloat64Open(t *testing.T) {
+	r := NewSuperKISS64(199)
+	forceNext(r, 0)
+	if x := r.Float64Open(); x != 0x1p-53 {
+		t.Errorf("smallest Float64Open is %v; want 2^-53", x)
+	}
+	forceNext(r, math.MaxUint64)
+	if x := r.Float64Open(); x != 1-0x1p-53 || 1-x == 0 {
+		t.Errorf("largest Float64Open is %v; want 1-2^-53", x)
+	}
+	bins := make([]int, 16)
+	for i := 0; i < 1000000; i++ {
+		x := r.Float64Open()
+		if x <= 0 || x >= 1 {
+			t.Fatalf("Float64Open returned %v", x)
+		}
+		bins[int(x*16)]++
+	}
+	if p := binsPValue(bins); p < alpha || p > 1-alpha {
+		t.Errorf("Float64Open bins %v have p-value %v", bins, p)
+	}
+}
+
+func TestAntitheticFloat64(t *testing.T) {
+	const draws = 1000000
+	r, r2 := NewSuperKISS64(211), NewSuperKISS64(211)
+	bins := make([]int, 16)
+	for i := 0; i < draws; i++ {
+		u, v := r.AntitheticFloat64()
+		if u != r2.Float64() || v != 1-u || v <= 0 || v > 1 {
+			t.Fatalf("AntitheticFloat64 returned %v, %v", u, v)
+		}
+		bins[int(u*16)]++
+	}
+	if p := binsPValue(bins); p < alpha || p > 1-alpha {
+		t.Errorf("AntitheticFloat64 u bins %v have p-value %v", bins, p)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	r := NewSuperKISS64(223)
+	if p := r.Position(); p != QSIZE64 {
+		t.Fatalf("Position after seeding = %d; want %d", p, QSIZE64)
+	}
+	for want := uint64(1); want <= QSIZE64; want++ {
+		r.Uint64()
+		if p := r.Position(); p != want {
+			t.Fatalf("Position = %d; want %d", p, want)
+		}
+	}
+	r.Uint64() // refill
+	if p := r.Position(); p != 1 {
+		t.Errorf("Position right after refill = %d; want 1", p)
+	}
+	r.Skip(QSIZE64)
+	if p := r.Position(); p != 1 {
+		t.Errorf("Position after skipping a full Q = %d; want 1", p)
+	}
+}
+
+func TestSeedSlice(t *testing.T) {
+	r := NewSuperKISS64FromSlice([]uint64{3, 1, 4, 1, 5, 9})
+	s, ok := r.SeedSlice()
+	if !ok || !slices.Equal(s, []uint64{3, 1, 4, 1, 5, 9}) {
+		t.Fatalf("SeedSlice returned %v, %v", s, ok)
+	}
+	r.Uint64()
+	c := r.Clone()
+	z := NewSuperKISS64FromSlice(s)
+	z.Uint64()
+	for i := 0; i < 1000; i++ {
+		if a, b := c.Uint64(), z.Uint64(); a != b {
+			t.Fatalf("reseeding from SeedSlice gave %#x; want %#x at %d",
+				b, a, i)
+		}
+	}
+	s[0] = 99 // changing the returned copy must not affect r
+	if s2, _ := r.SeedSlice(); s2[0] != 3 {
+		t.Error("SeedSlice returned r's own slice")
+	}
+	r.SeedFromBytes([]byte{1, 2, 3})
+	if s, ok := r.SeedSlice(); !ok || !slices.Equal(s, []uint64{0x030201}) {
+		t.Errorf("SeedSlice after SeedFromBytes returned %v, %v", s, ok)
+	}
+	// Only the elements SeedFromSlice uses are recorded.
+	long := make([]uint64, 2*QSIZE64)
+	for i := range long {
+		long[i] = uint64(i)
+	}
+	raw := make([]byte, 64)
+	r.SeedFromSlice(long)
+	s, _ = r.SeedSlice()
+	if len(s) != QSIZE64+1 {
+		t.Errorf("SeedSlice recorded %d elements; want %d", len(s), QSIZE64+1)
+	}
+	if z := NewSuperKISS64FromSlice(s); !z.Equal(r) {
+		t.Error("reseeding from a capped SeedSlice gave a different state")
+	}
+	for name, reseed := range map[string]func(){
+		"Seed":           func() { r.Seed(5) },
+		"SeedFromCrypto": r.SeedFromCrypto,
+		"Wipe":           r.Wipe,
+		"SeedMix":        func() { r.SeedMix(5, 6) },
+		"SeedFromString": func() { r.SeedFromString("run") },
+		"SeedFromReader": func() { r.SeedFromReader(bytes.NewReader(raw)) },
+	} {
+		r.SeedFromSlice([]uint64{1})
+		reseed()
+		if s, ok := r.SeedSlice(); ok || s != nil {
+			t.Errorf("SeedSlice after %s returned %v, %v", name, s, ok)
+		}
+	}
+	for name, g := range map[string]*SK64{
+		"NewSuperKISS64FromSliceCopy": NewSuperKISS64FromSliceCopy(long),
+		"NewForTask":                  NewForTask(1, 2),
+		"NewKeyedStream":              NewKeyedStream([]byte("key")),
+		"NewSubstreams":               NewSubstreams(7, 1)[0],
+	} {
+		if s, ok := g.SeedSlice(); ok || s != nil {
+			t.Errorf("SeedSlice of %s returned %v, %v", name, s, ok)
+		}
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	r := NewSuperKISS64(227) // a refill is due, so SelfTest must clone Q
+	for i := 0; i < 3; i++ {
+		before := r.Clone()
+		if err := r.SelfTest(); err != nil {
+			t.Fatalf("SelfTest of a healthy generator returned %v", err)
+		}
+		if !r.Equal(before) {
+			t.Fatal("SelfTest changed the state")
+		}
+		r.Uint64()
+	}
+	// Xs stays valid, so only the check of Q can catch these.
+	for _, n := range []int{QSIZE64, QSIZE64 * 3 / 4} {
+		bad := r.Clone()
+		for i := range bad.Q[:n] {
+			bad.Q[i] = 42
+		}
+		if err := bad.Validate(); err != nil {
+			t.Fatalf("Validate of a corrupted Q returned %v", err)
+		}
+		if err := bad.SelfTest(); err == nil {
+			t.Errorf("SelfTest passed a generator with %d of %d Q values equal",
+				n, QSIZE64)
+		}
+	}
+	// Craft Q so the next values are all identical, then all zero.
+	for _, v := range []uint64{0x1234, 0} {
+		stuck := r.Clone()
+		c := stuck.Clone()
+		for i := stuck.Index; i < stuck.Index+selfTestDraws; i++ {
+			c.Q[i] = 0
+			stuck.Q[i] = v - c.Uint64()
+		}
+		if err := stuck.SelfTest(); err == nil {
+			t.Errorf("SelfTest passed a generator stuck at %#x", v)
+		}
+	}
+}
+
+func TestNewKeyedStream(t *testing.T) {
+	key := []byte("test data masking key")
+	data := []byte(strings.Repeat("The quick brown fox. ", 200))
+	mask := func(p []byte, key []byte) []byte {
+		ks := make([]byte, len(p))
+		NewKeyedStream(key).Read(ks)
+		out := make([]byte, len(p))
+		for i := range p {
+			out[i] = p[i] ^ ks[i]
+		}
+		return out
+	}
+	masked := mask(data, key)
+	if bytes.Equal(masked, data) {
+		t.Fatal("masking did not change the data")
+	}
+	if got := mask(masked, key); !bytes.Equal(got, data) {
+		t.Error("masking twice with the same key did not recover the data")
+	}
+	if got := mask(masked, []byte("another key")); bytes.Equal(got, data) {
+		t.Error("a different key recovered the data")
+	}
+}
+
+func TestInt(t *testing.T) {
+	r := NewSuperKISS64(98)
+	var or int
+	for i := 0; i < 100000; i++ {
+		n := r.Int()
+		if n < 0 {
+			t.Fatalf("Int returned negative %d", n)
+		}
+		or |= n
+	}
+	if want := int(^uint(0) >> 1); or != want {
+		t.Errorf("Int bits OR to %#x, want %#x", or, want)
+	}
+}
+
+func TestNewForTask(t *testing.T) {
+	first := func(r *SK64) [4]uint64 {
+		return [4]uint64{r.Uint64(), r.Uint64(), r.Uint64(), r.Uint64()}
+	}
+	seen := map[[4]uint64][2]int64{}
+	for _, run := range []int64{0, 1, -1, 20261016} {
+		for task := 0; task < 50; task++ {
+			got := first(NewForTask(run, task))
+			if prev, ok := seen[got]; ok {
+				t.Fatalf("NewForTask(%d, %d) repeats the sequence of "+
+					"NewForTask(%d, %d)",
+					run, task, prev[0], prev[1])
+			}
+			seen[got] = [2]int64{run, int64(task)}
+			if again := first(NewForTask(run, task)); again != got {
+				t.Fatalf("NewForTask(%d, %d) is not reproducible", run, task)
+			}
+		}
+	}
+}
+
+// Calculate many p-values for many PRNG runs, then calculate the
+// p-value of the p-values, testing all p-values for acceptability.
+func pValueTest(rng io.Reader, t *testing.T) {
+	binsOfPValues := make([]int, 10)       // 10 bins for p-values
+	PValueCount := len(binsOfPValues) * 10 // 10 average per bin (min. 5 req'd)
+	for m := 0; m < PValueCount; m++ {
+		pValue := chiSquareUniformity(rng, 800000)
+		if pValue == 1.0 {
+			pValue -= alpha / 2 // prevent index out-of-range
+		}
+		binsOfPValues[int(pValue*float64(len(binsOfPValues)))]++
+		if pValue < alpha || pValue > 1-alpha {
+			t.Errorf("Extreme p-value: %.15g.  This test is not "+
+				"deterministic.\n", pValue)
+		}
+	}
+	// calculate p-value of p-values
+	expected := float64(PValueCount) / float64(len(binsOfPValues))
+	chiSquare := 0.0
+	for _, observed := range binsOfPValues {
+		x := float64(observed) - expected
+		chiSquare += x * x / expected
+	}
+	dof := len(binsOfPValues) - 1
+	pValue := PValue(dof, chiSquare)
+	if pValue < alpha || pValue > 1-alpha {
+		t.Errorf("Extreme p-value of p-values: %.15g.  This test is not "+
+			"deterministic.\n", pValue)
+	}
+}
+
+// binsPValue returns the chi-square p-value of bins, which should hold
+// counts of uniformly distributed values.
+func binsPValue(bins []int) float64 {
+	total := 0
+	for _, observed := range bins {
+		total += observed
+	}
+	expected := float64(total) / float64(len(bins))
+	chiSquare := 0.0
+	for _, observed := range bins {
+		x := float64(observed) - expected
+		chiSquare += x * x / expected
+	}
+	return PValue(len(bins)-1, chiSquare)
+}
+
+// isPerm reports whether p is a permutation of [0,len(p)).
+func isPerm(p []int) bool {
+	seen := make([]bool, len(p))
+	for _, v := range p {
+		if v < 0 || v >= len(p) || seen[v] {
+			return false
+		}
+		seen[v] = true
+	}
+	return true
+}
+
+// panics reports whether f panics.
+func panics(f func()) (p bool) {
+	defer func() {
+		p = recover() != nil
+	}()
+	f()
+	return
+}
+
+// Compile time test: CryptoSource implements the rand.Source interface.
+var _ rand.Source = &CryptoSource{}
+
+// Compile time test: CryptoSource implements the rand.Source64 interface.
+var _ rand.Source64 = &CryptoSource{}
+
+// Compile time test: CryptoSource implements the io.Reader interface.
+var _ io.Reader = &CryptoSource{}
+
+// Compile time test: CryptoSource implements the math/rand/v2.Source interface.
+var _ randv2.Source = &CryptoSource{}
+
+// SuperKISS64 implementation tests:
+// Compile time test: SK64 implements the rand.Source interface.
+var _ rand.Source = &SK64{}
+
+// Compile time test: SK64 implements the rand.Source64 interface.
+var _ rand.Source64 = &SK64{}
+
+// Compile time test: SK64 implements the io.Reader interface.
+var _ io.Reader = &SK64{}
+
+// Compile time test: SK64 implements the math/rand/v2.Source interface.
+var _ randv2.Source = &SK64{}
+
+// Compile time test: SK64 implements the encoding.BinaryMarshaler interface.
+var _ encoding.BinaryMarshaler = &SK64{}
+
+// Compile time test: SK64 implements the encoding.BinaryUnmarshaler interface.
+var _ encoding.BinaryUnmarshaler = &SK64{}
+
+// Compile time test: SK64 implements the encoding.BinaryAppender interface.
+var _ encoding.BinaryAppender = &SK64{}
+
+// Compile time test: SK64 implements the encoding.TextMarshaler interface.
+var _ encoding.TextMarshaler = &SK64{}
+
+// Compile time test: SK64 implements the encoding.TextUnmarshaler interface.
+var _ encoding.TextUnmarshaler = &SK64{}
+
+// Compile time test: SK64 implements the json.Marshaler interface.
+var _ json.Marshaler = &SK64{}
+
+// Compile time test: SK64 implements the json.Unmarshaler interface.
+var _ json.Unmarshaler = &SK64{}
+
+// Compile time test: SK64 implements the gob.GobEncoder interface.
+var _ gob.GobEncoder = &SK64{}
+
+// Compile time test: SK64 implements the gob.GobDecoder interface.
+var _ gob.GobDecoder = &SK64{}
+
+// Compile time test: SK64 implements the xml.Marshaler interface.
+var _ xml.Marshaler = &SK64{}
+
+// Compile time test: SK64 implements the xml.Unmarshaler interface.
+var _ xml.Unmarshaler = &SK64{}
+
+// Compile time test: SK64 implements the fmt.Stringer interface.
+var _ fmt.Stringer = &SK64{}
+
+// Compile time test: SK64 implements the io.ByteReader interface.
+var _ io.ByteReader = &SK64{}
+
+// Compile time test: LockedSK64 implements the rand.Source64 interface.
+var _ rand.Source64 = &LockedSK64{}
+
+// Compile time test: LockedSK64 implements the io.Reader interface.
+var _ io.Reader = &LockedSK64{}
+
+// Compile time test: LockedSK64 implements the math/rand/v2.Source interface.
+var _ randv2.Source = &LockedSK64{}
+
+// Compile time test: XorSource implements the rand.Source64 interface.
+var _ rand.Source64 = &XorSource{}
+
+// Compile time test: XorSource implements the io.Reader interface.
+var _ io.Reader = &XorSource{}
+
+// Compile time test: XorSource implements the math/rand/v2.Source interface.
+var _ randv2.Source = &XorSource{}
+
+//////////////////////////////////////////////////////////////////////////////
+//==========================================================================//
+//\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\\
+
+var v uint64
+var x float32
+var w []byte
+
+func BenchmarkCryptoSource(b *testing.B) {
+	b.SetBytes(8)
+	r := NewCryptoSource()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v = r.Uint64()
+	}
+}
+
+func BenchmarkSuperKISS64(b *testing.B) {
+	b.SetBytes(8)
+	r := NewSuperKISS64Rand()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v = r.Uint64()
+	}
+}
+
+func BenchmarkFloat32(b *testing.B) {
+	b.SetBytes(4)
+	r := NewSuperKISS64Rand()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x = r.Float32()
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	const size = 1e6
+	w = make([]byte, size)
+	b.SetBytes(size)
+	b.ReportAllocs()
+	r := NewSuperKISS64Rand()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Read(w)
+	}
+}
+
+func BenchmarkReset(b *testing.B) {
+	b.ReportAllocs()
+	r := NewSuperKISS64(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Reset(int64(i))
+	}
+}
+
+func BenchmarkNewSuperKISS64(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = NewSuperKISS64(int64(i))
+	}
+}
+
+func BenchmarkUint64n(b *testing.B) {
+	r := NewSuperKISS64Rand()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v = r.Uint64n(1000003)
+	}
+}
+
+func BenchmarkUint64Modulo(b *testing.B) {
+	r := NewSuperKISS64Rand()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v = r.Uint64() % 1000003
+	}
+}
+
+func BenchmarkSkip(b *testing.B) {
+	b.SetBytes(8 * QSIZE64)
+	r := NewSuperKISS64Rand()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Skip(QSIZE64)
+	}
+}
+
+var u64s = make([]uint64, 4096)
+
+func BenchmarkFillUint64(b *testing.B) {
+	b.SetBytes(int64(8 * len(u64s)))
+	r := NewSuperKISS64Rand()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.FillUint64(u64s)
+	}
+}
+
+func BenchmarkUint64Loop(b *testing.B) {
+	b.SetBytes(int64(8 * len(u64s)))
+	r := NewSuperKISS64Rand()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range u64s {
+			u64s[j] = r.Uint64()
+		}
+	}
+}
+
+var f64s = make([]float64, 4096)
+
+func BenchmarkFillFloat64(b *testing.B) {
+	b.SetBytes(int64(8 * len(f64s)))
+	r := NewSuperKISS64Rand()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.FillFloat64(f64s)
+	}
+}
+
+func BenchmarkFloat64Loop(b *testing.B) {
+	b.SetBytes(int64(8 * len(f64s)))
+	r := NewSuperKISS64Rand()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range f64s {
+			f64s[j] = r.Float64()
+		}
+	}
+}
+
+func BenchmarkSeed(b *testing.B) {
+	r := NewSuperKISS64(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Seed(int64(i) + 1)
+	}
+}
+
+func BenchmarkSeedFast(b *testing.B) {
+	r := NewSuperKISS64(1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.SeedFast(int64(i) + 1)
+	}
+}
+
+func BenchmarkRefill(b *testing.B) {
+	r := NewSuperKISS64(1)
+	b.SetBytes(QSIZE64 * 8)
+	for i := 0; i < b.N; i++ {
+		r.refill()
 	}
 }
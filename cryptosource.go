@@ -10,9 +10,11 @@
 package SuperKISS64
 
 import (
+	"context"
 	crand "crypto/rand"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"reflect"
 )
 
@@ -25,6 +27,7 @@ var u64bytes = int(reflect.TypeOf(csDummy).Size())
 type CryptoSource struct {
 	buf  []byte
 	next int
+	rd   io.Reader // entropy source; nil means crypto/rand.Reader
 }
 
 // NewCryptoSource returns a cryptographically-based math/rand.Source.
@@ -45,8 +48,17 @@ type CryptoSource struct {
 // callers must synchronize access using sync.Mutex or similar, or allocate
 // multiple instances of CryptoSource.
 func NewCryptoSource() *CryptoSource {
-	// multiplicand was empirically optimized on a 3.2 GHz 2020 M1 Mac mini
-	bufSize := u64bytes * 32
+	// bufWords was empirically optimized on a 3.2 GHz 2020 M1 Mac mini
+	return NewCryptoSourceSize(32)
+}
+
+// NewCryptoSourceSize is like NewCryptoSource, but the returned
+// CryptoSource buffers bufWords uint64 values from crypto/rand instead of
+// the default 32.  A larger buffer helps where each crypto/rand read is
+// expensive; a smaller one saves memory.  bufWords values less than 1 are
+// treated as 1.
+func NewCryptoSourceSize(bufWords int) *CryptoSource {
+	bufSize := u64bytes * max(bufWords, 1)
 
 	return &CryptoSource{
 		buf:  make([]byte, bufSize),
@@ -54,18 +66,49 @@ func NewCryptoSource() *CryptoSource {
 	}
 }
 
+// NewCryptoSourceWithReader is like NewCryptoSource, but the returned
+// CryptoSource reads its entropy from rd instead of crypto/rand.Reader,
+// for example a hardware RNG or, in tests, a reader of fixed content.
+// A nil rd means crypto/rand.Reader.
+func NewCryptoSourceWithReader(rd io.Reader) *CryptoSource {
+	r := NewCryptoSource()
+	r.rd = rd
+	return r
+}
+
 // Seed is part of the math/rand.Source interface.  Seed is a noop.
 func (r *CryptoSource) Seed(seed int64) {
 	// noop
 }
 
+// reader returns the entropy source of r.
+func (r *CryptoSource) reader() io.Reader {
+	if r.rd == nil {
+		return crand.Reader
+	}
+	return r.rd
+}
+
 // Uint64 returns a uniformly-distributed, pseudorandom 64-bit value in
-// the range [0,2^64) from CryptoSource.
-// This method implements the math/rand.Source64 interface.
-func (r *CryptoSource) Uint64() (n uint64) {
+// the range [0,2^64) from CryptoSource.  Uint64 panics if reading from
+// crypto/rand fails; see TryUint64 for an alternative.
+// This method implements the math/rand.Source64 and math/rand/v2.Source
+// interfaces.
+func (r *CryptoSource) Uint64() uint64 {
+	n, err := r.TryUint64()
+	if err != nil {
+		panic(fmt.Sprintf("crypto/rand.Read error in CryptoSource.Uint64: %v", err))
+	}
+	return n
+}
+
+// TryUint64 is like Uint64, but returns an error instead of panicking if
+// reading from crypto/rand fails.  After an error r remains usable, and
+// a later call can succeed if the failure was transient.
+func (r *CryptoSource) TryUint64() (n uint64, err error) {
 	if r.next >= len(r.buf) {
-		if _, err := crand.Read(r.buf); err != nil {
-			panic(fmt.Sprintf("crypto/rand.Read error in CryptoSource.Uint64: %v", err))
+		if _, err = io.ReadFull(r.reader(), r.buf); err != nil {
+			return
 		}
 		r.next = 0
 	}
@@ -76,6 +119,27 @@ func (r *CryptoSource) Uint64() (n uint64) {
 	return
 }
 
+// Remaining returns the number of buffered random bytes that r has read
+// but not yet dispensed through Uint64, TryUint64 or Int63.  Read and
+// Fill do not use the buffer.
+func (r *CryptoSource) Remaining() int {
+	return len(r.buf) - r.next
+}
+
+// Reset discards r's buffered random bytes, so the next Uint64, TryUint64
+// or Int63 call refills the buffer.
+func (r *CryptoSource) Reset() {
+	r.next = len(r.buf)
+}
+
+// Fill fills p with random bytes from crypto/rand, or from the reader
+// given to NewCryptoSourceWithReader.  It returns nil if and only if all
+// of p was filled.
+func (r *CryptoSource) Fill(p []byte) error {
+	_, err := io.ReadFull(r.reader(), p)
+	return err
+}
+
 // Int63 returns a uniformly-distributed, pseudorandom 64-bit value in
 // the range [0,2^63) from CryptoSource.
 // This method is part of the math/rand.Source interface.
@@ -83,9 +147,33 @@ func (r *CryptoSource) Int63() int64 {
 	return int64(r.Uint64() >> 1)
 }
 
-// Read fills p with pseudorandom bytes from crypto/rand.  n is the number
-// of bytes read into p; err is the error indicator.  n == len(p) iff err == nil.
+// Read fills p with pseudorandom bytes from crypto/rand, or from the reader
+// given to NewCryptoSourceWithReader.  n is the number of bytes read into
+// p; err is the error indicator.  n == len(p) iff err == nil.
 // This method implements the io.Reader interface.
 func (r *CryptoSource) Read(p []byte) (n int, err error) {
-	return crand.Read(p)
+	return io.ReadFull(r.reader(), p)
+}
+
+// readContextChunk is how many bytes ReadContext reads between checks of
+// its context.
+const readContextChunk = 4096
+
+// ReadContext is like Read, but checks ctx before reading each chunk of
+// up to 4096 bytes and stops early with ctx.Err() if ctx is done, so a
+// long read honors cancellation.  n is the number of bytes read into p.
+func (r *CryptoSource) ReadContext(ctx context.Context,
+	p []byte) (n int, err error) {
+	for n < len(p) {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		var m int
+		m, err = io.ReadFull(r.reader(), p[n:min(n+readContextChunk, len(p))])
+		n += m
+		if err != nil {
+			return
+		}
+	}
+	return
 }
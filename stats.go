@@ -0,0 +1,304 @@
+// stats.go - statistical checks of the output of SuperKISS64 generators,
+// for users who want to test their own seeded generators.
+//
+// Ron Charlton's additions are public domain as per CC0 1.0; see
+// <https://creativecommons.org/publicdomain/zero/1.0/> for information.
+
+package SuperKISS64
+
+import (
+	"io"
+	"math"
+	"slices"
+)
+
+// Ported by Ron Charlton from p_value.c on 2018-08-29.
+// From https://www.codeproject.com/Articles/432194/How-to-Calculate-the-Chi-Squared-P-Value
+// on 2018-08-29, where it was named chisqr.c. I (Ron) included gamma.c's
+// relevant function within this file for simplicity.  My port to the Go
+// programming language is in the public domain, as is the original.
+
+// Comment from chisqr.c:
+/*  Implementation of the Chi-Square Distribution, Gamma Function &
+	Incomplete Gamma Function in C
+
+	Written By Jacob Wells
+	July 31, 2012
+    Based on the formulas found here:
+
+    Wikipedia - Incomplete Gamma Function -> Evaluation formulae -> Connection
+	with Kummer's confluent hypergeometric function
+    http://en.wikipedia.org/wiki/Regularized_Gamma_function#Connection_with_Kummer.27s_confluent_hypergeometric_function
+
+    Wikipedia - Chi-squared Distribution -> Cumulative distribution function
+    http://en.wikipedia.org/wiki/Chi-squared_distribution#Cumulative_distribution_function
+
+    These functions are placed in the Public Domain, and may be used by anyone,
+	anywhere, for any reason, absolutely free of charge.
+*/
+
+/*
+c:\Users\Ron\go\src>go run TestPValue.go 255 160
+0.999999393099
+
+c:\Users\Ron\go\src>go run TestPValue.go 255 250
+0.57663526365
+
+c:\Users\Ron\go\src>go run TestPValue.go 255 300
+0.02772752205332
+*/
+
+// Dof is degrees of freedom.  Cv is critical value (chi-square).
+// Cv is sum( (observed - expected)^2 / expected )
+
+// PValue returns a p-value when given a degrees-of-freedom value and a
+// chi-square Critical value.
+func PValue(Dof int, Cv float64) float64 {
+	if Cv < 0 || Dof < 1 {
+		return 0.0
+	}
+
+	K := float64(Dof) * 0.5
+	X := Cv * 0.5
+
+	if Dof == 2 {
+		return math.Exp(-X)
+	}
+
+	PValue := 1.0 - math.Exp(LogIgamma(K, X)-LogGamma(K))
+
+	return PValue
+}
+
+/*
+	Returns the Natural Logarithm of the Incomplete Gamma Function.
+
+	I converted the p-value to work with Logarithms, and only calculate
+	the finished Value right at the end.  This allows us much more accurate
+	calculations.  One result of this is that I had to increase the Number
+	of Iterations from 200 to 1000.  Feel free to play around with this if
+	you like, but this is the only way I've gotten it to work.
+	Also, to make the code easier to work, I separated out the main loop.
+*/
+
+// LogIgamma returns the natural logarithm of the lower Incomplete Gamma
+// Function.
+func LogIgamma(S, Z float64) float64 {
+
+	if Z < 0.0 {
+		return 0.0
+	}
+
+	Sc := (math.Log(Z) * S) - Z - math.Log(S)
+
+	K := km(S, Z)
+
+	return math.Log(K) + Sc
+}
+
+func km(S, Z float64) float64 {
+	Sum := 1.0
+	Num := 1.0
+	Denom := 1.0
+
+	for I := 0; I < 1000; I++ {
+		Num *= Z
+		S++
+		Denom *= S
+		// The if statement was added by Ron Charlton to prevent invalidating
+		// Sum when using float64 numbers.
+		if Denom > 1.0e307 || Num > 1.0e307 {
+			break
+		}
+		Sum += (Num / Denom)
+	}
+
+	return Sum
+}
+
+// from gamma.c
+/*
+    Implementation of the Gamma function using Spouge's Approximation in C.
+
+    Written By Jacob F. Wells
+	7/31/2012
+    Public Domain
+
+    This code may be used by anyone for any reason
+    with no restrictions absolutely free of cost.
+*/
+
+const spougeA float64 = 11 // Spouge's a; 15 for long double
+/*
+    'a' (spougeA) is the level of accuracy you wish to calculate.
+    Spouge's Approximation is slightly tricky, as you
+    can only reach the desired level of precision if
+    you have EXTRA precision available so that it can
+    build up to the desired level.
+
+    If you're using double (64 bit wide datatype), you
+    will need to set spougeA to 11, as well as remember to
+    change the math functions to the regular
+    (i.e. pow() instead of powl())
+
+   !! IF YOU GO OVER OR UNDER THESE VALUES YOU WILL !!!
+              !!! LOSE PRECISION !!!
+*/
+
+// LogGamma returns the natural logarithm of Gamma function using Spouge's
+// Approximation. The Gamma Function allows you to compute the Factorial
+// of decimals (e.g. 5.5!).
+func LogGamma(N float64) float64 {
+	// The constant SQRT2PI is defined as sqrt(2.0 * PI);
+	// For speed the constant is already defined in decimal
+	// form.  However, if you wish to ensure that you achieve
+	// maximum precision on your own machine, you can calculate
+	// it yourself using (sqrt(atan(1.0) * 8.0))
+
+	//var SQRT2PI float64 = math.Sqrt(math.Atan(1.0) * 8.0)
+	const SQRT2PI float64 = 2.5066282746310005024157652848110452530069867406099383
+
+	Z := N
+
+	Sc := (math.Log(Z+spougeA) * (Z + 0.5)) - (Z + spougeA) - math.Log(Z)
+
+	F := 1.0
+	Sum := SQRT2PI
+
+	for K := float64(1); K < spougeA; K++ {
+		Z++
+		Ck := math.Pow(spougeA-K, K-0.5)
+		Ck *= math.Exp(spougeA - K)
+		Ck /= F
+
+		Sum += Ck / Z
+
+		F *= -K
+	}
+
+	return math.Log(Sum) + Sc
+}
+
+// ChiSquareUniformity reads sampleBytes bytes from SuperKISS64 generator r,
+// counts how often each of the 256 possible byte values occurs, and
+// returns the chi-square p-value for the hypothesis that the bytes are
+// uniformly distributed.  A good generator yields p-values that are
+// themselves uniform in [0,1]; p-values extremely close to 0 or 1, such as
+// outside George Marsaglia's [0.00001,0.99999], suggest a problem.
+// sampleBytes should be at least several thousand.  ChiSquareUniformity
+// panics if sampleBytes < 1.
+func ChiSquareUniformity(r *SK64, sampleBytes int) (pValue float64) {
+	return chiSquareUniformity(r, sampleBytes)
+}
+
+// chiSquareUniformity is ChiSquareUniformity for any io.Reader.
+func chiSquareUniformity(rd io.Reader, sampleBytes int) (pValue float64) {
+	const binCount = 256 // a byte can store 256 different values
+	if sampleBytes < 1 {
+		panic("SuperKISS64:ChiSquareUniformity called with sampleBytes < 1")
+	}
+	bins := make([]int, binCount)
+	buf := make([]byte, min(sampleBytes, 64*1024))
+	for left := sampleBytes; left > 0; left -= len(buf) {
+		buf = buf[:min(left, len(buf))]
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			panic("SuperKISS64:ChiSquareUniformity read error: " + err.Error())
+		}
+		for _, b := range buf {
+			bins[int(b)]++
+		}
+	}
+	expected := float64(sampleBytes) / binCount
+	chiSquare := 0.0
+	for _, observed := range bins {
+		x := float64(observed) - expected
+		chiSquare += x * x / expected
+	}
+	return PValue(binCount-1, chiSquare)
+}
+
+// KSTestUniform draws samples values from SuperKISS64 generator r via
+// Float64 and returns the Kolmogorov-Smirnov statistic D, the largest
+// distance between their empirical CDF and the uniform [0,1) CDF, along
+// with an approximate p-value for the hypothesis that the values are
+// uniform.  It checks the float conversion that ChiSquareUniformity does
+// not.  KSTestUniform panics if samples < 1.
+func KSTestUniform(r *SK64, samples int) (statistic, pValue float64) {
+	if samples < 1 {
+		panic("SuperKISS64:KSTestUniform called with samples < 1")
+	}
+	x := make([]float64, samples)
+	for i := range x {
+		x[i] = r.Float64()
+	}
+	slices.Sort(x)
+	n := float64(samples)
+	for i, v := range x {
+		statistic = max(statistic, float64(i+1)/n-v, v-float64(i)/n)
+	}
+	en := math.Sqrt(n)
+	pValue = ksProb((en + 0.12 + 0.11/en) * statistic)
+	return
+}
+
+// ksProb returns the Kolmogorov distribution's Q_KS(lambda), the
+// probability of a D at least as large as observed.  From Numerical
+// Recipes in C, 2nd ed., function probks.
+func ksProb(lambda float64) float64 {
+	const eps1, eps2 = 0.001, 1.0e-8
+	a2 := -2.0 * lambda * lambda
+	fac, sum, termbf := 2.0, 0.0, 0.0
+	for j := 1; j <= 100; j++ {
+		term := fac * math.Exp(a2*float64(j*j))
+		sum += term
+		if math.Abs(term) <= eps1*termbf || math.Abs(term) <= eps2*sum {
+			return sum
+		}
+		fac = -fac
+		termbf = math.Abs(term)
+	}
+	return 1.0 // failed to converge; lambda is near 0
+}
+
+// SerialCorrelation draws samples values from SuperKISS64 generator r via
+// Float64 and returns their lag-1 serial correlation coefficient, which is
+// near zero (within a few multiples of 1/sqrt(samples)) for a good
+// generator.  It is a quick smoke test of a custom-seeded generator.
+// SerialCorrelation panics if samples < 2.
+func SerialCorrelation(r *SK64, samples int) float64 {
+	if samples < 2 {
+		panic("SuperKISS64:SerialCorrelation called with samples < 2")
+	}
+	x := make([]float64, samples)
+	mean := 0.0
+	for i := range x {
+		x[i] = r.Float64()
+		mean += x[i]
+	}
+	mean /= float64(samples)
+	num, den := 0.0, 0.0
+	for i, v := range x {
+		d := v - mean
+		den += d * d
+		if i > 0 {
+			num += (x[i-1] - mean) * d
+		}
+	}
+	return num / den
+}
+
+// Histogram draws samples values from SuperKISS64 generator r via Float64,
+// sorts them into buckets equal-width bins over [0,1), and returns the
+// count in each bin.  It gives a quick look at uniformity for demos and
+// documentation.  Histogram panics if buckets < 1 or samples < 0.
+func (r *SK64) Histogram(buckets, samples int) []int {
+	if buckets < 1 || samples < 0 {
+		panic("SuperKISS64:Histogram called with buckets < 1 or samples < 0")
+	}
+	counts := make([]int, buckets)
+	for i := 0; i < samples; i++ {
+		b := int(r.Float64() * float64(buckets))
+		counts[min(b, buckets-1)]++ // guard against rounding up to buckets
+	}
+	return counts
+}